@@ -0,0 +1,9 @@
+// Package adapter converts polygons from other Go geometry libraries into
+// polylabel.Polygon values.
+//
+// Each adapter lives behind a build tag so that the corresponding dependency
+// is only required when it is used:
+//
+//	go build -tags orb   // FromOrb, for github.com/paulmach/orb
+//	go build -tags geom  // FromGeom, for github.com/twpayne/go-geom
+package adapter
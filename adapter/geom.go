@@ -0,0 +1,26 @@
+//go:build geom
+
+package adapter
+
+import (
+	polylabel "github.com/snorfalorpagus/polylabel-go"
+	"github.com/twpayne/go-geom"
+)
+
+// FromGeom converts a *geom.Polygon into a polylabel.Polygon. Any coordinate
+// dimensions beyond X and Y are dropped. A nil polygon gives a nil result.
+func FromGeom(p *geom.Polygon) polylabel.Polygon {
+	if p == nil {
+		return nil
+	}
+	polygon := make(polylabel.Polygon, p.NumLinearRings())
+	for i := range polygon {
+		coords := p.LinearRing(i).Coords()
+		ring := make(polylabel.Ring, len(coords))
+		for j, c := range coords {
+			ring[j] = polylabel.Coord{c.X(), c.Y()}
+		}
+		polygon[i] = ring
+	}
+	return polygon
+}
@@ -0,0 +1,21 @@
+//go:build orb
+
+package adapter
+
+import (
+	"github.com/paulmach/orb"
+	polylabel "github.com/snorfalorpagus/polylabel-go"
+)
+
+// FromOrb converts an orb.Polygon into a polylabel.Polygon.
+func FromOrb(p orb.Polygon) polylabel.Polygon {
+	polygon := make(polylabel.Polygon, len(p))
+	for i, r := range p {
+		ring := make(polylabel.Ring, len(r))
+		for j, pt := range r {
+			ring[j] = polylabel.Coord{pt[0], pt[1]}
+		}
+		polygon[i] = ring
+	}
+	return polygon
+}
@@ -0,0 +1,87 @@
+package polylabel
+
+import "math"
+
+// CurvedVertex is a vertex of a ring whose edge to the next vertex may be a
+// circular arc. Bulge is the tangent of a quarter of the angle the arc turns
+// through, as in DXF polylines: 0 is a straight edge, 1 is a semicircle, and a
+// positive bulge curves counterclockwise from this vertex to the next.
+type CurvedVertex struct {
+	Coord
+	Bulge float64
+}
+
+// CurvedRing is a closed ring of vertices joined by straight edges or arcs.
+// The last vertex is joined back to the first, so the first vertex should not
+// be repeated at the end.
+type CurvedRing []CurvedVertex
+
+// FlattenArcs converts curved rings into a Polygon by replacing each arc with
+// straight segments that stray from it by at most tolerance. Flattening moves
+// the outline by up to tolerance, so a tolerance no larger than the precision
+// the polygon will be labelled at keeps the label accurate to that precision.
+func FlattenArcs(rings []CurvedRing, tolerance float64) Polygon {
+	polygon := make(Polygon, 0, len(rings))
+	for _, curved := range rings {
+		if len(curved) == 0 {
+			continue
+		}
+		ring := make(Ring, 0, len(curved)+1)
+		for i, v := range curved {
+			next := curved[(i+1)%len(curved)].Coord
+			ring = append(ring, v.Coord)
+			ring = flattenArc(ring, v.Coord, next, v.Bulge, tolerance)
+		}
+		ring = append(ring, curved[0].Coord)
+		polygon = append(polygon, ring)
+	}
+	return polygon
+}
+
+// append the interior points of the arc from a to b with the given bulge,
+// spaced so that no segment strays from the arc by more than tolerance
+func flattenArc(ring Ring, a Coord, b Coord, bulge float64, tolerance float64) Ring {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	chord := math.Hypot(dx, dy)
+	if bulge == 0 || chord == 0 || math.IsNaN(bulge) || math.IsInf(bulge, 0) {
+		return ring
+	}
+
+	// the center is off the middle of the chord, to the left for a
+	// counterclockwise arc of less than a half turn
+	angle := 4 * math.Atan(bulge)
+	offset := chord / 2 / math.Tan(angle/2)
+	cx := (a[0]+b[0])/2 - dy/chord*offset
+	cy := (a[1]+b[1])/2 + dx/chord*offset
+	radius := math.Hypot(a[0]-cx, a[1]-cy)
+
+	segments := arcSegments(radius, angle, tolerance)
+	start := math.Atan2(a[1]-cy, a[0]-cx)
+	for i := 1; i < segments; i++ {
+		theta := start + angle*float64(i)/float64(segments)
+		ring = append(ring, Coord{cx + radius*math.Cos(theta), cy + radius*math.Sin(theta)})
+	}
+	return ring
+}
+
+// get the number of segments needed to stay within tolerance of an arc of
+// the given radius turning through angle radians, using one per degree if
+// tolerance is not positive
+func arcSegments(radius float64, angle float64, tolerance float64) int {
+	// a segment spanning step radians strays by radius*(1 - cos(step/2))
+	step := math.Pi
+	if tolerance <= 0 {
+		step = math.Pi / 180
+	} else if tolerance < radius {
+		step = 2 * math.Acos(1-tolerance/radius)
+	}
+	segments := math.Ceil(math.Abs(angle) / step)
+	if !(segments >= 1) {
+		return 1
+	}
+	return int(math.Min(segments, maxCurveSegments))
+}
+
+// curves are never split into more segments than this, however tight the
+// tolerance
+const maxCurveSegments = 1 << 16
@@ -0,0 +1,45 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlattenArcs(t *testing.T) {
+	// a straight edged square is unchanged apart from closing the ring
+	square := CurvedRing{{Coord{0, 0}, 0}, {Coord{2, 0}, 0}, {Coord{2, 2}, 0}, {Coord{0, 2}, 0}}
+	polygon := FlattenArcs([]CurvedRing{square}, 0.1)
+	AssertEqual(t, len(polygon), 1)
+	AssertEqual(t, len(polygon[0]), 5)
+	AssertEqual(t, polygon[0][4], Coord{0, 0})
+
+	// two counterclockwise semicircles make a circle of radius 1
+	tolerance := 0.001
+	circle := CurvedRing{{Coord{1, 0}, 1}, {Coord{-1, 0}, 1}}
+	polygon = FlattenArcs([]CurvedRing{circle}, tolerance)
+	for _, p := range polygon[0] {
+		if r := math.Hypot(p[0], p[1]); math.Abs(r-1) > 1e-9 {
+			t.Errorf("Received %v, expected a point on the unit circle", p)
+		}
+	}
+	for i := 0; i < len(polygon[0])-1; i++ {
+		a, b := polygon[0][i], polygon[0][i+1]
+		if sagitta := 1 - math.Hypot((a[0]+b[0])/2, (a[1]+b[1])/2); sagitta > tolerance {
+			t.Errorf("Received a segment %v, %v straying %v from the arc", a, b, sagitta)
+		}
+	}
+	if ringArea(polygon[0]) <= 0 {
+		t.Error("Expected a counterclockwise ring")
+	}
+
+	result := PolylabelVerbose(polygon, tolerance)
+	if math.Abs(result.X) > 0.01 || math.Abs(result.Y) > 0.01 || math.Abs(result.Distance-1) > 0.01 {
+		t.Errorf("Received %v, expected the unit circle center", result)
+	}
+
+	// a negative bulge curves the other way
+	polygon = FlattenArcs([]CurvedRing{{{Coord{1, 0}, -1}, {Coord{-1, 0}, -1}}}, tolerance)
+	if ringArea(polygon[0]) >= 0 {
+		t.Error("Expected a clockwise ring")
+	}
+}
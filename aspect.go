@@ -0,0 +1,41 @@
+package polylabel
+
+import "math"
+
+// WithAspectRatio fills in Result.Aspect with the ratio of the width to the
+// height of the free space through the label, measured along the x and y
+// axes to the outline. A ratio above 1 suits horizontal text and one below 1
+// suits stacked text.
+func WithAspectRatio(enabled bool) Option {
+	return func(o *options) {
+		o.aspect = enabled
+	}
+}
+
+// steps taken along each axis before giving up on reaching the outline
+const maxTraceSteps = 64
+
+// get the ratio of the width to the height of the free space through a point
+// inside the polygon, or 0 if the point is not inside
+func localAspect(x float64, y float64, precision float64, cellAt cellFunc) float64 {
+	d := cellAt(x, y, 0).d
+	if !(d > 0) {
+		return 0
+	}
+	tolerance := math.Max(precision, d*1e-6)
+	width := traceToOutline(x, y, 1, 0, d, tolerance, cellAt) + traceToOutline(x, y, -1, 0, d, tolerance, cellAt)
+	height := traceToOutline(x, y, 0, 1, d, tolerance, cellAt) + traceToOutline(x, y, 0, -1, d, tolerance, cellAt)
+	return width / height
+}
+
+// get the distance from a point to the outline in a direction, by stepping
+// along it by the distance to the outline until within tolerance of it; d is
+// the distance at the starting point
+func traceToOutline(x float64, y float64, dx float64, dy float64, d float64, tolerance float64, cellAt cellFunc) float64 {
+	t := 0.0
+	for i := 0; i < maxTraceSteps && d > tolerance; i++ {
+		t += d
+		d = cellAt(x+dx*t, y+dy*t, 0).d
+	}
+	return t + math.Max(d, 0)
+}
@@ -0,0 +1,30 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWithAspectRatio(t *testing.T) {
+	wide := Polygon{Ring{Coord{0, 0}, Coord{8, 0}, Coord{8, 2}, Coord{0, 2}, Coord{0, 0}}}
+	result := PolylabelVerbose(wide, 0.01, WithAspectRatio(true))
+	if math.Abs(result.Aspect-4) > 0.05 {
+		t.Errorf("Received %v, expected 4", result.Aspect)
+	}
+	AssertEqual(t, PolylabelVerbose(wide, 0.01).Aspect, 0.0)
+
+	tall := Polygon{Ring{Coord{0, 0}, Coord{2, 0}, Coord{2, 8}, Coord{0, 8}, Coord{0, 0}}}
+	result = PolylabelVerbose(tall, 0.01, WithAspectRatio(true))
+	if math.Abs(result.Aspect-0.25) > 0.01 {
+		t.Errorf("Received %v, expected 0.25", result.Aspect)
+	}
+
+	// an oblique outline is approached in many steps
+	diamond := Polygon{Ring{Coord{0, 0}, Coord{6, -1}, Coord{12, 0}, Coord{6, 1}, Coord{0, 0}}}
+	result = PolylabelVerbose(diamond, 0.001, WithAspectRatio(true))
+	if math.Abs(result.Aspect-6) > 0.1 {
+		t.Errorf("Received %v, expected 6", result.Aspect)
+	}
+
+	AssertEqual(t, localAspect(-1, -1, 0.01, newOptions(nil).cellFunc(wide)), 0.0)
+}
@@ -0,0 +1,24 @@
+package polylabel
+
+import "context"
+
+// cells processed between checks for cancellation
+const asyncBatchSize = 256
+
+// PolylabelAsync is like PolylabelVerbose but searches on a new goroutine,
+// sending the result on the returned channel when done and then closing it.
+// If ctx is cancelled first, the search stops early and the best label found
+// so far is sent instead, as from Search.Result; ctx.Err() tells the two
+// apart. The channel is buffered, so the goroutine finishes even if the
+// result is never received.
+func PolylabelAsync(ctx context.Context, polygon Polygon, precision float64, opts ...Option) <-chan Result {
+	results := make(chan Result, 1)
+	go func() {
+		defer close(results)
+		search := NewSearch(polygon, precision, opts...)
+		for ctx.Err() == nil && !search.Run(asyncBatchSize) {
+		}
+		results <- search.Result()
+	}()
+	return results
+}
@@ -0,0 +1,30 @@
+package polylabel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelAsync(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+	results := PolylabelAsync(context.Background(), polygon, 1.0)
+	if result := <-results; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	if _, ok := <-results; ok {
+		t.Error("Expected the channel to be closed after the result")
+	}
+
+	// a cancelled search still sends the best label found so far
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, ok := <-PolylabelAsync(ctx, polygon, 1e-9)
+	if !ok {
+		t.Fatal("Expected a result from a cancelled search")
+	}
+	if initial := NewSearch(polygon, 1e-9).Result(); !reflect.DeepEqual(result, initial) {
+		t.Errorf("Received %v, expected the initial best guess %v", result, initial)
+	}
+}
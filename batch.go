@@ -0,0 +1,65 @@
+package polylabel
+
+import "sort"
+
+// RankedResult is the label of one polygon of a batch.
+type RankedResult struct {
+	Result
+	// Index is the position of the polygon in the batch.
+	Index int
+}
+
+// LabelBatchRanked labels each of polygons and returns the labels sorted by
+// their distance from the outline, largest first, so that the most prominent
+// labels can be placed first and those overlapping them dropped. Labels with
+// equal distances keep the order of their polygons in the batch. An empty
+// polygon is given a zero Result. The polygons are labelled one after
+// another with a single Labeler.
+func LabelBatchRanked(polygons []Polygon, precision float64, opts ...Option) []RankedResult {
+	l := NewLabeler(opts...)
+	results := make([]RankedResult, len(polygons))
+	for i, polygon := range polygons {
+		results[i].Index = i
+		if len(polygon) > 0 && len(polygon[0]) > 0 {
+			results[i].Result = l.Label(polygon, precision)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance > results[j].Distance
+	})
+	return results
+}
+
+// LabelBatchFunc labels each of polygons at the precision precisionFor gives
+// for it, for example one scaled to its size, and returns the labels in the
+// order of the polygons. precisionFor is called once for each polygon, just
+// before it is labelled. An empty polygon is given a zero Result without
+// calling precisionFor. The polygons are labelled one after another with a
+// single Labeler.
+func LabelBatchFunc(polygons []Polygon, precisionFor func(Polygon) float64, opts ...Option) []Result {
+	l := NewLabeler(opts...)
+	results := make([]Result, len(polygons))
+	for i, polygon := range polygons {
+		if len(polygon) > 0 && len(polygon[0]) > 0 {
+			results[i] = l.Label(polygon, precisionFor(polygon))
+		}
+	}
+	return results
+}
+
+// LabelByID labels each of features and returns the labels under the same
+// keys, for joining them back to the features they came from. An empty
+// polygon is given a zero Result. The polygons are labelled one after
+// another with a single Labeler.
+func LabelByID(features map[string]Polygon, precision float64, opts ...Option) map[string]Result {
+	l := NewLabeler(opts...)
+	results := make(map[string]Result, len(features))
+	for id, polygon := range features {
+		var result Result
+		if len(polygon) > 0 && len(polygon[0]) > 0 {
+			result = l.Label(polygon, precision)
+		}
+		results[id] = result
+	}
+	return results
+}
@@ -0,0 +1,68 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLabelBatchRanked(t *testing.T) {
+	square := func(size float64) Polygon {
+		return Polygon{Ring{Coord{0, 0}, Coord{size, 0}, Coord{size, size}, Coord{0, size}, Coord{0, 0}}}
+	}
+	water := loadData("test_data/water1.json")
+	polygons := []Polygon{square(2), water, {}, square(10), square(2)}
+	results := LabelBatchRanked(polygons, 1.0)
+
+	AssertEqual(t, len(results), len(polygons))
+	var order []int
+	for _, result := range results {
+		order = append(order, result.Index)
+	}
+	// the two equal squares keep their order, ahead of the empty polygon
+	if expected := []int{1, 3, 0, 4, 2}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Received %v, expected %v", order, expected)
+	}
+	if expected := PolylabelVerbose(water, 1.0); !reflect.DeepEqual(results[0].Result, expected) {
+		t.Errorf("Received %v, expected %v", results[0].Result, expected)
+	}
+	AssertEqual(t, results[4].Distance, 0.0)
+
+	AssertEqual(t, len(LabelBatchRanked(nil, 1.0)), 0)
+}
+
+func TestLabelBatchFunc(t *testing.T) {
+	water := loadData("test_data/water1.json")
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	polygons := []Polygon{water, {}, square}
+
+	// a precision of a hundredth of the size of each polygon
+	calls := 0
+	results := LabelBatchFunc(polygons, func(polygon Polygon) float64 {
+		calls++
+		minX, minY, maxX, maxY := boundingBox(polygon)
+		return math.Max(maxX-minX, maxY-minY) / 100
+	})
+	AssertEqual(t, calls, 2)
+	expected := []Result{PolylabelVerbose(water, 43.52), {}, PolylabelVerbose(square, 0.04)}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Received %v, expected %v", results, expected)
+	}
+}
+
+func TestLabelByID(t *testing.T) {
+	water := loadData("test_data/water1.json")
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	results := LabelByID(map[string]Polygon{"water": water, "square": square, "empty": {}}, 1.0)
+
+	expected := map[string]Result{
+		"water":  PolylabelVerbose(water, 1.0),
+		"square": PolylabelVerbose(square, 1.0),
+		"empty":  {},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Received %v, expected %v", results, expected)
+	}
+
+	AssertEqual(t, len(LabelByID(nil, 1.0)), 0)
+}
@@ -0,0 +1,316 @@
+package polylabel
+
+import "math/big"
+
+// BigCoord is a point given as an [x, y] pair of arbitrary precision values.
+type BigCoord [2]*big.Float
+
+// BigRing is a closed sequence of BigCoord, as for Ring.
+type BigRing []BigCoord
+
+// BigPolygon is a list of BigRing, the first being the exterior and any
+// further rings holes, as for Polygon.
+type BigPolygon []BigRing
+
+// BigResult holds a label found by PolylabelBig and its distance from the
+// polygon outline.
+type BigResult struct {
+	X        *big.Float
+	Y        *big.Float
+	Distance *big.Float
+}
+
+// ToBigPolygon converts polygon to a BigPolygon whose values have prec bits
+// of mantissa.
+func ToBigPolygon(polygon Polygon, prec uint) BigPolygon {
+	rings := make(BigPolygon, len(polygon))
+	for i, ring := range polygon {
+		rings[i] = make(BigRing, len(ring))
+		for n, coord := range ring {
+			rings[i][n] = BigCoord{
+				new(big.Float).SetPrec(prec).SetFloat64(coord[0]),
+				new(big.Float).SetPrec(prec).SetFloat64(coord[1]),
+			}
+		}
+	}
+	return rings
+}
+
+// PolylabelBig is like PolylabelVerbose but computes the distances and cells
+// with big.Float values of prec bits of mantissa, for coordinates that need
+// more than the 53 bits of a float64. It is many times slower than
+// PolylabelVerbose, does not take options and uses the even-odd containment
+// rule. Open rings are closed, as for Polylabel. Cells are not split once
+// their children could not be told apart at prec bits, so precision may be
+// zero to search as far as prec allows.
+func PolylabelBig(polygon BigPolygon, precision *big.Float, prec uint) BigResult {
+	m := bigMath{prec}
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return BigResult{m.float(0), m.float(0), m.float(0)}
+	}
+	polygon = closeBigRings(polygon)
+
+	// bounding box of the exterior ring
+	minX, minY := m.copy(polygon[0][0][0]), m.copy(polygon[0][0][1])
+	maxX, maxY := m.copy(minX), m.copy(minY)
+	for _, coord := range polygon[0] {
+		if coord[0].Cmp(minX) < 0 {
+			minX.Set(coord[0])
+		}
+		if coord[0].Cmp(maxX) > 0 {
+			maxX.Set(coord[0])
+		}
+		if coord[1].Cmp(minY) < 0 {
+			minY.Set(coord[1])
+		}
+		if coord[1].Cmp(maxY) > 0 {
+			maxY.Set(coord[1])
+		}
+	}
+
+	width, height := m.sub(maxX, minX), m.sub(maxY, minY)
+	cellSize := width
+	if height.Cmp(width) < 0 {
+		cellSize = height
+	}
+	midX, midY := m.quo(m.add(minX, maxX), m.float(2)), m.quo(m.add(minY, maxY), m.float(2))
+	if cellSize.Sign() == 0 {
+		// the middle of a polygon that is a line or a point
+		return BigResult{midX, midY, m.float(0)}
+	}
+	h := m.quo(cellSize, m.float(2))
+
+	q := bigQueue{m: m}
+	cellAt := func(x, y, h *big.Float) *bigCell {
+		return m.newCell(x, y, h, m.distance(x, y, polygon))
+	}
+
+	// take centroid as the first best guess, unless the bounding box center
+	// is as good
+	cx, cy := m.centroid(polygon[0])
+	best := cellAt(cx, cy, m.float(0))
+	bboxCell := cellAt(midX, midY, m.float(0))
+	if bboxCell.d.Cmp(best.d) >= 0 {
+		best = bboxCell
+	}
+
+	// cover polygon with initial cells, counted as for newCellSearch; the
+	// width and height are small enough next to the cell size to count in
+	// float64 even where the coordinates are not
+	w, _ := m.quo(width, cellSize).Float64()
+	ht, _ := m.quo(height, cellSize).Float64()
+	columns, rows := cellCount(w, 1), cellCount(ht, 1)
+	for i := 0; i < columns; i++ {
+		x := m.add(minX, m.mul(m.float(float64(i)), cellSize))
+		for j := 0; j < rows; j++ {
+			y := m.add(minY, m.mul(m.float(float64(j)), cellSize))
+			q.push(cellAt(m.add(x, h), m.add(y, h), h))
+		}
+	}
+
+	for len(q.queue) > 0 {
+		c := q.pop()
+		if c.d.Cmp(best.d) > 0 {
+			best = c
+		}
+
+		// do not drill down further if there's no chance of a better solution
+		if m.sub(c.max, best.d).Cmp(precision) <= 0 {
+			continue
+		}
+
+		// or if the children could not be told apart at this precision
+		h := m.quo(c.h, m.float(2))
+		if m.add(c.x, h).Cmp(c.x) == 0 || m.add(c.y, h).Cmp(c.y) == 0 {
+			continue
+		}
+
+		// split the cell into four cells
+		q.push(cellAt(m.sub(c.x, h), m.sub(c.y, h), h))
+		q.push(cellAt(m.add(c.x, h), m.sub(c.y, h), h))
+		q.push(cellAt(m.sub(c.x, h), m.add(c.y, h), h))
+		q.push(cellAt(m.add(c.x, h), m.add(c.y, h), h))
+	}
+
+	return BigResult{best.x, best.y, best.d}
+}
+
+// close any rings whose last coordinate does not repeat the first, as for
+// closeRings
+func closeBigRings(polygon BigPolygon) BigPolygon {
+	closed := polygon
+	copied := false
+	for i, ring := range polygon {
+		if len(ring) == 0 {
+			continue
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0].Cmp(last[0]) == 0 && first[1].Cmp(last[1]) == 0 {
+			continue
+		}
+		if !copied {
+			closed = append(BigPolygon(nil), polygon...)
+			copied = true
+		}
+		closed[i] = append(append(make(BigRing, 0, len(ring)+1), ring...), first)
+	}
+	return closed
+}
+
+type bigCell struct {
+	x   *big.Float
+	y   *big.Float
+	h   *big.Float
+	d   *big.Float
+	max *big.Float
+}
+
+// a cellQueue of big cells, each queued as a cell holding its max rounded to
+// float64 and, in x, its index in cells. The rounding can only swap the order
+// of cells whose maxes agree to 53 bits, and the search compares the big
+// values before it prunes a cell.
+type bigQueue struct {
+	m     bigMath
+	queue cellQueue
+	cells []*bigCell
+	free  []int // indices of cells that have been popped
+}
+
+func (q *bigQueue) push(c *bigCell) {
+	i := len(q.cells)
+	if n := len(q.free); n > 0 {
+		i, q.free = q.free[n-1], q.free[:n-1]
+		q.cells[i] = c
+	} else {
+		q.cells = append(q.cells, c)
+	}
+	max, _ := c.max.Float64()
+	q.queue.push(cell{x: float64(i), max: max}, false)
+}
+
+func (q *bigQueue) pop() *bigCell {
+	i := int(q.queue.pop(false).x)
+	c := q.cells[i]
+	q.cells[i] = nil
+	q.free = append(q.free, i)
+	return c
+}
+
+// arithmetic on new values of a fixed precision, leaving the operands alone
+type bigMath struct {
+	prec uint
+}
+
+func (m bigMath) float(v float64) *big.Float {
+	return new(big.Float).SetPrec(m.prec).SetFloat64(v)
+}
+
+func (m bigMath) copy(a *big.Float) *big.Float {
+	return new(big.Float).SetPrec(m.prec).Set(a)
+}
+
+func (m bigMath) add(a, b *big.Float) *big.Float {
+	return new(big.Float).SetPrec(m.prec).Add(a, b)
+}
+
+func (m bigMath) sub(a, b *big.Float) *big.Float {
+	return new(big.Float).SetPrec(m.prec).Sub(a, b)
+}
+
+func (m bigMath) mul(a, b *big.Float) *big.Float {
+	return new(big.Float).SetPrec(m.prec).Mul(a, b)
+}
+
+func (m bigMath) quo(a, b *big.Float) *big.Float {
+	return new(big.Float).SetPrec(m.prec).Quo(a, b)
+}
+
+// a cell with distance d from the polygon outline at its center, as for
+// newCell
+func (m bigMath) newCell(x, y, h, d *big.Float) *bigCell {
+	diagonal := new(big.Float).SetPrec(m.prec).Sqrt(m.float(2))
+	return &bigCell{x, y, h, d, m.add(d, m.mul(h, diagonal))}
+}
+
+// signed distance from point to polygon outline (negative if point is
+// outside), as for pointToPolygonDistance
+func (m bigMath) distance(x, y *big.Float, polygon BigPolygon) *big.Float {
+	inside := false
+	var minDistSq *big.Float
+
+	for _, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			a := ring[n]
+			b := ring[n+1]
+			if m.rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			if distSq := m.segmentDistanceSquared(x, y, a, b); minDistSq == nil || distSq.Cmp(minDistSq) < 0 {
+				minDistSq = distSq
+			}
+		}
+	}
+
+	if minDistSq == nil {
+		return m.float(0)
+	}
+	d := new(big.Float).SetPrec(m.prec).Sqrt(minDistSq)
+	if !inside {
+		d.Neg(d)
+	}
+	return d
+}
+
+// report whether a ray from the point to the right crosses segment a, b, as
+// for rayCrosses
+func (m bigMath) rayCrosses(x, y *big.Float, a, b BigCoord) bool {
+	if (a[1].Cmp(y) > 0) == (b[1].Cmp(y) > 0) {
+		return false
+	}
+	t := m.quo(m.mul(m.sub(b[0], a[0]), m.sub(y, a[1])), m.sub(b[1], a[1]))
+	return x.Cmp(m.add(t, a[0])) < 0
+}
+
+// get squared distance from a point to a segment, as for
+// segmentDistanceSquared
+func (m bigMath) segmentDistanceSquared(px, py *big.Float, a, b BigCoord) *big.Float {
+	x, y := a[0], a[1]
+	dx, dy := m.sub(b[0], x), m.sub(b[1], y)
+
+	if dx.Sign() != 0 || dy.Sign() != 0 {
+		t := m.quo(
+			m.add(m.mul(m.sub(px, x), dx), m.mul(m.sub(py, y), dy)),
+			m.add(m.mul(dx, dx), m.mul(dy, dy)),
+		)
+		if t.Cmp(m.float(1)) > 0 {
+			x, y = b[0], b[1]
+		} else if t.Sign() > 0 {
+			x, y = m.add(x, m.mul(dx, t)), m.add(y, m.mul(dy, t))
+		}
+	}
+
+	dx, dy = m.sub(px, x), m.sub(py, y)
+	return m.add(m.mul(dx, dx), m.mul(dy, dy))
+}
+
+// get the centroid of a ring, working relative to its first vertex so that
+// coordinates far from the origin do not cancel out, as for getCentroid
+func (m bigMath) centroid(ring BigRing) (*big.Float, *big.Float) {
+	originX, originY := ring[0][0], ring[0][1]
+	area, areaMagnitude, x, y := m.float(0), m.float(0), m.float(0), m.float(0)
+	for n := 0; n < (len(ring) - 1); n++ {
+		ax, ay := m.sub(ring[n][0], originX), m.sub(ring[n][1], originY)
+		bx, by := m.sub(ring[n+1][0], originX), m.sub(ring[n+1][1], originY)
+		f := m.sub(m.mul(ax, by), m.mul(bx, ay))
+		x = m.add(x, m.mul(m.add(ax, bx), f))
+		y = m.add(y, m.mul(m.add(ay, by), f))
+		area = m.add(area, m.mul(f, m.float(3)))
+		areaMagnitude = m.add(areaMagnitude, new(big.Float).Abs(m.mul(f, m.float(3))))
+	}
+	// an area lost in the rounding of its terms is treated as zero, as for
+	// getCentroid
+	if new(big.Float).Abs(area).Cmp(m.mul(m.float(centroidAreaEpsilon), areaMagnitude)) <= 0 {
+		return m.copy(originX), m.copy(originY)
+	}
+	return m.add(originX, m.quo(x, area)), m.add(originY, m.quo(y, area))
+}
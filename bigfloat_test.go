@@ -0,0 +1,82 @@
+package polylabel
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestPolylabelBig(t *testing.T) {
+	polygon := SyntheticPolygon(32)
+	expected := PolylabelVerbose(polygon, 0.1)
+	result := PolylabelBig(ToBigPolygon(polygon, 53), big.NewFloat(0.1), 53)
+	x, _ := result.X.Float64()
+	y, _ := result.Y.Float64()
+	AssertEqual(t, x, expected.X)
+	AssertEqual(t, y, expected.Y)
+
+	// a 2 by 2 square far beyond the reach of float64, whose coordinates
+	// would all round to 1e30
+	m := bigMath{200}
+	offset := func(v float64) *big.Float {
+		return m.add(m.float(1e30), m.float(v))
+	}
+	rectangle := BigPolygon{BigRing{
+		{offset(0), offset(0)},
+		{offset(2), offset(0)},
+		{offset(2), offset(2)},
+		{offset(0), offset(2)},
+		{offset(0), offset(0)},
+	}}
+	result = PolylabelBig(rectangle, big.NewFloat(1e-6), 200)
+	distance, _ := result.Distance.Float64()
+	AssertEqual(t, distance, 1.0)
+	for _, v := range []*big.Float{result.X, result.Y} {
+		if m.sub(v, offset(1)).Sign() != 0 {
+			t.Errorf("Received %v, expected %v", v, offset(1))
+		}
+	}
+
+	result = PolylabelBig(BigPolygon{}, big.NewFloat(1), 53)
+	if result.X.Sign() != 0 || result.Y.Sign() != 0 || result.Distance.Sign() != 0 {
+		t.Errorf("Received %v for an empty polygon", result)
+	}
+}
+
+func TestPolylabelBigOpenRing(t *testing.T) {
+	// a ring that does not repeat its first coordinate is closed, as it is by
+	// Polylabel
+	open := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{0, 10}}}
+	expected := PolylabelVerbose(open, 0.1)
+	result := PolylabelBig(ToBigPolygon(open, 53), big.NewFloat(0.1), 53)
+	x, _ := result.X.Float64()
+	y, _ := result.Y.Float64()
+	AssertEqual(t, x, expected.X)
+	AssertEqual(t, y, expected.Y)
+}
+
+func TestBigCentroidOfNearCoincidentVertices(t *testing.T) {
+	// the ring of TestCentroidOfNearCoincidentVertices, whose area is lost to
+	// rounding error, falls back to the first vertex as for getCentroid
+	x0, y0 := 1e6, 1e6
+	up := math.Nextafter(x0+1, 2e6)
+	polygon := ToBigPolygon(Polygon{Ring{
+		Coord{x0, y0}, Coord{x0 + 1, y0}, Coord{x0 + 1, y0 + 1}, Coord{x0, y0 + 1},
+		Coord{x0, up}, Coord{up, y0 + 1}, Coord{up, y0}, Coord{x0, y0},
+	}}, 53)
+	x, y := bigMath{53}.centroid(polygon[0])
+	fx, _ := x.Float64()
+	fy, _ := y.Float64()
+	AssertEqual(t, fx, x0)
+	AssertEqual(t, fy, y0)
+}
+
+func TestPolylabelBigLine(t *testing.T) {
+	// a line is labelled at its middle, as by Polylabel
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{0, 5}, Coord{0, 10}, Coord{0, 0}}}
+	result := PolylabelBig(ToBigPolygon(polygon, 53), big.NewFloat(1), 53)
+	x, _ := result.X.Float64()
+	y, _ := result.Y.Float64()
+	AssertEqual(t, Coord{x, y}, Coord{0, 5})
+	AssertEqual(t, result.Distance.Sign(), 0)
+}
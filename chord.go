@@ -0,0 +1,83 @@
+package polylabel
+
+import "math"
+
+// directions through the label tried by LongestInteriorChord, before the
+// best of them is refined
+const chordDirections = 180
+
+// LongestInteriorChord returns the label of polygon found by PolylabelVerbose
+// and the ends a and b of an approximately longest segment through it that
+// stays inside the polygon, for placing a rotated label along an elongated
+// shape. Each end is where the segment first meets the outline. Only
+// segments through the label are tried, in 180 directions and then finer
+// around the best, so a longer segment elsewhere, or one through the label
+// that is only inside because it grazes a vertex, may be missed. The ends
+// are both the label if it is not inside the polygon. Options that move the
+// returned label off the pole, such as output rounding or transforms, also
+// move the segment.
+func LongestInteriorChord(polygon Polygon, precision float64, opts ...Option) (label Result, a Coord, b Coord) {
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return Result{}, Coord{}, Coord{}
+	}
+	label = PolylabelVerbose(polygon, precision, opts...)
+	p := Coord{label.X, label.Y}
+	if !(label.Distance > 0) {
+		return label, p, p
+	}
+	polygon = closeRings(polygon)
+
+	chord := func(angle float64) (float64, Coord, Coord) {
+		dy, dx := math.Sincos(angle)
+		forward := rayToOutline(p, dx, dy, polygon)
+		backward := rayToOutline(p, -dx, -dy, polygon)
+		return forward + backward,
+			Coord{p[0] + forward*dx, p[1] + forward*dy},
+			Coord{p[0] - backward*dx, p[1] - backward*dy}
+	}
+
+	best, bestLength := 0.0, -1.0
+	for i := 0; i < chordDirections; i++ {
+		angle := math.Pi * float64(i) / chordDirections
+		if length, _, _ := chord(angle); length > bestLength {
+			best, bestLength = angle, length
+		}
+	}
+
+	// refine the direction, halving the step each time
+	for step := math.Pi / chordDirections / 2; step > 1e-9; step /= 2 {
+		for _, angle := range [2]float64{best - step, best + step} {
+			if length, _, _ := chord(angle); length > bestLength {
+				best, bestLength = angle, length
+			}
+		}
+	}
+	_, a, b = chord(best)
+	return label, a, b
+}
+
+// get the distance along the ray from p in direction dx, dy to the nearest
+// edge of polygon, or 0 if it meets none
+func rayToOutline(p Coord, dx float64, dy float64, polygon Polygon) float64 {
+	nearest := math.Inf(1)
+	for _, ring := range polygon {
+		for n := 0; n < len(ring)-1; n++ {
+			a, b := ring[n], ring[n+1]
+			ex, ey := b[0]-a[0], b[1]-a[1]
+			denom := dx*ey - dy*ex
+			if denom == 0 {
+				continue
+			}
+			ax, ay := a[0]-p[0], a[1]-p[1]
+			t := (ax*ey - ay*ex) / denom
+			s := (ax*dy - ay*dx) / denom
+			if t > 0 && s >= 0 && s <= 1 && t < nearest {
+				nearest = t
+			}
+		}
+	}
+	if math.IsInf(nearest, 1) {
+		return 0
+	}
+	return nearest
+}
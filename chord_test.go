@@ -0,0 +1,40 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLongestInteriorChord(t *testing.T) {
+	// the longest segment through the center of a rectangle is a diagonal
+	rectangle := Polygon{Ring{Coord{0, 0}, Coord{8, 0}, Coord{8, 2}, Coord{0, 2}, Coord{0, 0}}}
+	label, a, b := LongestInteriorChord(rectangle, 0.01)
+	if math.Abs(math.Hypot(b[0]-a[0], b[1]-a[1])-math.Hypot(8, 2)) > 1e-6 {
+		t.Errorf("Received %v to %v through %v, expected a diagonal", a, b, label)
+	}
+
+	// a thin diagonal bar is crossed along its length
+	bar := Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{11, 10}, Coord{11, 11}, Coord{10, 11}, Coord{0, 1}, Coord{0, 0}}}
+	label, a, b = LongestInteriorChord(bar, 0.01)
+	if length := math.Hypot(b[0]-a[0], b[1]-a[1]); length < 14 {
+		t.Errorf("Received %v to %v, length %v, expected the length of the bar", a, b, length)
+	}
+	for _, end := range []Coord{a, b} {
+		if d := pointToPolygonDistance(end[0], end[1], bar); math.Abs(d) > 1e-9 {
+			t.Errorf("Received an end %v at distance %v, expected it on the outline", end, d)
+		}
+	}
+	if label.Distance <= 0 {
+		t.Errorf("Received %v, expected a label inside", label)
+	}
+
+	// the ends are the label where there is no interior
+	line := Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 0}}}
+	label, a, b = LongestInteriorChord(line, 0.01)
+	AssertEqual(t, a, Coord{label.X, label.Y})
+	AssertEqual(t, b, a)
+
+	label, a, b = LongestInteriorChord(Polygon{}, 0.01)
+	AssertEqual(t, label.Distance, 0.0)
+	AssertEqual(t, a, Coord{})
+}
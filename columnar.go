@@ -0,0 +1,97 @@
+package polylabel
+
+import (
+	"errors"
+	"math"
+)
+
+// PolygonFromXY builds a polygon from coordinates stored as parallel slices of
+// x and y values. Ring i is made up of the coordinates from ringOffsets[i] up
+// to but not including ringOffsets[i+1], so ringOffsets has one more element
+// than there are rings and ends with len(xs).
+func PolygonFromXY(xs []float64, ys []float64, ringOffsets []int) (Polygon, error) {
+	if err := checkXY(xs, ys, ringOffsets); err != nil {
+		return nil, err
+	}
+	polygon := make(Polygon, len(ringOffsets)-1)
+	for i := range polygon {
+		start, end := ringOffsets[i], ringOffsets[i+1]
+		ring := make(Ring, end-start)
+		for j := range ring {
+			ring[j] = Coord{xs[start+j], ys[start+j]}
+		}
+		polygon[i] = ring
+	}
+	return polygon, nil
+}
+
+// check that parallel slices and ring offsets describe a polygon, as for
+// PolygonFromXY
+func checkXY(xs []float64, ys []float64, ringOffsets []int) error {
+	if len(xs) != len(ys) {
+		return errors.New("polylabel: xs and ys have different lengths")
+	}
+	if len(ringOffsets) < 2 {
+		return errors.New("polylabel: ring offsets must describe at least one ring")
+	}
+	if ringOffsets[0] != 0 || ringOffsets[len(ringOffsets)-1] != len(xs) {
+		return errors.New("polylabel: ring offsets must start at 0 and end at the number of coordinates")
+	}
+	for i := 0; i+1 < len(ringOffsets); i++ {
+		if ringOffsets[i+1] <= ringOffsets[i] {
+			return errors.New("polylabel: ring offsets must be increasing")
+		}
+	}
+	return nil
+}
+
+// PolylabelXY labels a polygon stored as parallel slices of coordinates, as
+// described by PolygonFromXY. Coordinates are read from the slices as
+// distances are evaluated, so the polygon is never interleaved into Coord
+// pairs apart from its exterior ring, which is copied once. The result is the
+// same as labelling the equivalent Polygon, with the options limited as for
+// PolylabelSource.
+func PolylabelXY(xs []float64, ys []float64, ringOffsets []int, precision float64, opts ...Option) (Result, error) {
+	if err := checkXY(xs, ys, ringOffsets); err != nil {
+		return Result{}, err
+	}
+	exterior := make(Ring, ringOffsets[1])
+	for n := range exterior {
+		exterior[n] = Coord{xs[n], ys[n]}
+	}
+	bounds, cx, cy := ringsExtent([]Ring{closeRings(Polygon{exterior})[0]})
+	return labelDistance(bounds, cx, cy, func(x, y float64) float64 {
+		return pointToXYDistance(x, y, xs, ys, ringOffsets)
+	}, precision, opts), nil
+}
+
+// signed distance from point to the outline of a polygon stored as parallel
+// slices (negative if point is outside), as for pointToPolygonDistance; each
+// ring is closed by an edge from its last coordinate back to its first,
+// which has no length if the ring is already closed
+func pointToXYDistance(x float64, y float64, xs []float64, ys []float64, ringOffsets []int) float64 {
+	inside := false
+	minDistSq := math.Inf(1)
+
+	for i := 0; i+1 < len(ringOffsets); i++ {
+		start, end := ringOffsets[i], ringOffsets[i+1]
+		for n := start; n < end; n++ {
+			next := n + 1
+			if next == end {
+				next = start
+			}
+			a := Coord{xs[n], ys[n]}
+			b := Coord{xs[next], ys[next]}
+			if rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
+		}
+	}
+
+	factor := 1.0
+	if !inside {
+		factor = -1.0
+	}
+	return factor * math.Sqrt(minDistSq)
+}
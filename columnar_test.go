@@ -0,0 +1,52 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelXY(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	var xs, ys []float64
+	ringOffsets := []int{0}
+	for _, ring := range polygon {
+		for _, coord := range ring {
+			xs = append(xs, coord[0])
+			ys = append(ys, coord[1])
+		}
+		ringOffsets = append(ringOffsets, len(xs))
+	}
+
+	result, err := PolylabelXY(xs, ys, ringOffsets, 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PolylabelVerbose(polygon, 1.0)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	// open rings are closed, as for the nested API
+	xs, ys = []float64{0, 4, 4, 0}, []float64{0, 0, 4, 4}
+	result, err = PolylabelXY(xs, ys, []int{0, 4}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = PolylabelVerbose(Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}}}, 0.1)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestPolygonFromXYErrors(t *testing.T) {
+	xs := []float64{0, 1, 1, 0}
+	ys := []float64{0, 0, 1, 0}
+	for _, ringOffsets := range [][]int{nil, {0}, {1, 4}, {0, 3}, {0, 2, 2, 4}} {
+		if _, err := PolygonFromXY(xs, ys, ringOffsets); err == nil {
+			t.Errorf("Expected an error for ring offsets %v", ringOffsets)
+		}
+	}
+	if _, err := PolygonFromXY(xs, ys[:3], []int{0, 3}); err == nil {
+		t.Error("Expected an error for mismatched slices")
+	}
+}
@@ -0,0 +1,67 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithCompactness(t *testing.T) {
+	// a square 10 across joined by a neck to a strip 10.4 across, which has
+	// the most room but is long and thin
+	polygon := Polygon{Ring{
+		Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{14, 4}, Coord{14, -0.2}, Coord{74, -0.2},
+		Coord{74, 10.2}, Coord{14, 10.2}, Coord{14, 6}, Coord{10, 6}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0},
+	}}
+	if result := PolylabelVerbose(polygon, 0.5); result.X < 14 {
+		t.Fatalf("Received %v, expected a label in the strip", result)
+	}
+	result := PolylabelVerbose(polygon, 0.5, WithCompactness(1))
+	if result.X > 10 || result.Distance < 4.5 {
+		t.Errorf("Received %v, expected a label in the square", result)
+	}
+	AssertEqual(t, result.Distance, pointToPolygonDistance(result.X, result.Y, polygon))
+
+	// a circle is perfectly compact, and a strip about half
+	square := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	distance := func(x, y float64) float64 { return pointToPolygonDistance(x, y, square) }
+	if c := compactness(5, 5, 5, distance); c < 0.7 || c > 0.8 {
+		t.Errorf("Received %v, expected about 0.77 in a square", c)
+	}
+	circle := regularPolygon(256, 10, 0, 0, 0)
+	distance = func(x, y float64) float64 { return pointToPolygonDistance(x, y, circle) }
+	if c := compactness(0, 0, pointToPolygonDistance(0, 0, circle), distance); c < 0.99 {
+		t.Errorf("Received %v, expected about 1 in a circle", c)
+	}
+
+	// a weight of zero is the distance alone
+	polygon = loadData("test_data/water1.json")
+	if result, expected := PolylabelVerbose(polygon, 1.0, WithCompactness(0)), PolylabelVerbose(polygon, 1.0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestWithCompactnessSource(t *testing.T) {
+	// the functions that know the polygon only by its distance score and
+	// report labels as PolylabelVerbose does
+	polygon := Polygon{Ring{
+		Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{14, 4}, Coord{14, -0.2}, Coord{74, -0.2},
+		Coord{74, 10.2}, Coord{14, 10.2}, Coord{14, 6}, Coord{10, 6}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0},
+	}}
+	expected := PolylabelVerbose(polygon, 0.5, WithCompactness(1))
+	if result := PolylabelSource(polygon, 0.5, WithCompactness(1)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	// and so does PolylabelInteger, given the polygon scaled to integers
+	scaled := Polygon{make(Ring, len(polygon[0]))}
+	for n, c := range polygon[0] {
+		scaled[0][n] = Coord{c[0] * 5, c[1] * 5}
+	}
+	result, err := PolylabelInteger(scaled, 1, WithCompactness(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.X > 50 || result.Distance != pointToPolygonDistance(result.X, result.Y, scaled) {
+		t.Errorf("Received %v, expected a label in the square", result)
+	}
+}
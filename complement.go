@@ -0,0 +1,28 @@
+package polylabel
+
+import "math"
+
+// PolylabelComplement finds the point of frame farthest from polygon and from
+// the edges of frame: the center of the largest empty circle outside polygon
+// that fits in frame, for placing a legend or other annotation clear of a
+// feature. The holes of polygon are outside it, so the label may be in a
+// hole. The Distance of the result is the radius of the circle. polygon may
+// extend beyond frame or be empty, in which case the label is the center of
+// frame. The search starts from the center of frame, and the options are
+// limited as for PolylabelSource.
+func PolylabelComplement(polygon Polygon, frame Rect, precision float64, opts ...Option) Result {
+	var rings []Ring
+	for _, ring := range closeRings(polygon) {
+		if len(ring) > 0 {
+			rings = append(rings, ring)
+		}
+	}
+	bounds, cx, cy := ringsExtent(frame.ToPolygon())
+	return labelDistance(bounds, cx, cy, func(x, y float64) float64 {
+		d := rectDistance(frame.MinX, frame.MinY, frame.MaxX, frame.MaxY, x, y)
+		if len(rings) == 0 {
+			return d
+		}
+		return math.Min(d, -pointToPolygonDistance(x, y, rings))
+	}, precision, opts)
+}
@@ -0,0 +1,33 @@
+package polylabel
+
+import "testing"
+
+func TestPolylabelComplement(t *testing.T) {
+	// a square over the left half of the frame leaves a square on the right
+	square := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	frame := Rect{0, 0, 20, 10}
+	result := PolylabelComplement(square, frame, 0.01)
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{15, 5})
+	AssertEqual(t, result.Distance, 5.0)
+
+	// a hole is outside the polygon
+	frame = Rect{0, 0, 10, 10}
+	holed := append(square, Ring{Coord{2, 2}, Coord{2, 8}, Coord{8, 8}, Coord{8, 2}, Coord{2, 2}})
+	result = PolylabelComplement(holed, frame, 0.01)
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{5, 5})
+	AssertEqual(t, result.Distance, 3.0)
+
+	// the label stays clear of a complex polygon within its bounding box
+	polygon := loadData("test_data/water1.json")
+	minX, minY, maxX, maxY := boundingBox(polygon)
+	frame = Rect{minX, minY, maxX, maxY}
+	result = PolylabelComplement(polygon, frame, 1.0)
+	if result.Distance <= 0 || pointToPolygonDistance(result.X, result.Y, polygon) > -result.Distance {
+		t.Errorf("Received %v, expected a label outside the polygon", result)
+	}
+
+	// an empty polygon leaves the whole frame
+	result = PolylabelComplement(nil, Rect{0, 0, 4, 4}, 0.01)
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{2, 2})
+	AssertEqual(t, result.Distance, 2.0)
+}
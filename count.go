@@ -0,0 +1,20 @@
+package polylabel
+
+// RingCount returns the number of rings of polygon, including the exterior.
+func RingCount(polygon Polygon) int {
+	return len(polygon)
+}
+
+// VertexCount returns the number of coordinates across all rings of polygon.
+// If distinct is set, the last coordinate of a ring is not counted when it
+// repeats the first, so a closed square counts as 4 vertices rather than 5.
+func VertexCount(polygon Polygon, distinct bool) int {
+	n := 0
+	for _, ring := range polygon {
+		n += len(ring)
+		if distinct && len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+			n--
+		}
+	}
+	return n
+}
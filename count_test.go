@@ -0,0 +1,21 @@
+package polylabel
+
+import "testing"
+
+func TestVertexCount(t *testing.T) {
+	polygon := Polygon{
+		Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}},
+		Ring{Coord{2, 2}, Coord{2, 4}, Coord{4, 4}}, // not closed
+	}
+	AssertEqual(t, RingCount(polygon), 2)
+	AssertEqual(t, VertexCount(polygon, false), 8)
+	AssertEqual(t, VertexCount(polygon, true), 7)
+
+	AssertEqual(t, RingCount(Polygon{}), 0)
+	AssertEqual(t, VertexCount(Polygon{}, true), 0)
+	AssertEqual(t, VertexCount(Polygon{Ring{Coord{1, 1}}}, true), 1)
+
+	water := loadData("test_data/water1.json")
+	AssertEqual(t, VertexCount(water, false), 5030)
+	AssertEqual(t, VertexCount(water, true), 5030-len(water))
+}
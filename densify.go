@@ -0,0 +1,36 @@
+package polylabel
+
+import "math"
+
+// Densify returns a copy of polygon with extra vertices inserted along every
+// edge longer than maxSegmentLength, splitting it into equal parts no longer
+// than that. Densifying is not needed for ordinary labelling, where the
+// distance to each edge is exact however long it is. It is useful with
+// WithEdgeWeights, to give parts of a long edge their own weights, and before
+// projecting a polygon, so that its edges follow the projection. polygon is
+// not modified, and a maxSegmentLength that is not positive returns a copy
+// unchanged.
+func Densify(polygon Polygon, maxSegmentLength float64) Polygon {
+	densified := make(Polygon, len(polygon))
+	for i, ring := range polygon {
+		if len(ring) == 0 {
+			densified[i] = Ring{}
+			continue
+		}
+		out := make(Ring, 0, len(ring))
+		for n := 0; n < len(ring)-1; n++ {
+			a, b := ring[n], ring[n+1]
+			out = append(out, a)
+			if !(maxSegmentLength > 0) {
+				continue
+			}
+			parts := math.Ceil(math.Hypot(b[0]-a[0], b[1]-a[1]) / maxSegmentLength)
+			for k := 1.0; k < parts; k++ {
+				t := k / parts
+				out = append(out, Coord{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])})
+			}
+		}
+		densified[i] = append(out, ring[len(ring)-1])
+	}
+	return densified
+}
@@ -0,0 +1,54 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDensify(t *testing.T) {
+	polygon := Polygon{
+		Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 3}, Coord{0, 3}, Coord{0, 0}},
+		Ring{Coord{1, 1}, Coord{1, 2}, Coord{2, 2}, Coord{1, 1}},
+	}
+	densified := Densify(polygon, 4)
+	expected := Polygon{
+		Ring{
+			Coord{0, 0}, Coord{10.0 / 3, 0}, Coord{20.0 / 3, 0}, Coord{10, 0}, Coord{10, 3},
+			Coord{20.0 / 3, 3}, Coord{10.0 / 3, 3}, Coord{0, 3}, Coord{0, 0},
+		},
+		polygon[1],
+	}
+	AssertEqual(t, len(densified), len(expected))
+	for i := range expected {
+		AssertEqual(t, len(densified[i]), len(expected[i]))
+		for n, c := range expected[i] {
+			if d := densified[i][n]; math.Abs(d[0]-c[0]) > 1e-12 || math.Abs(d[1]-c[1]) > 1e-12 {
+				t.Errorf("Received %v, expected %v", d, c)
+			}
+		}
+	}
+
+	// the polygon keeps its shape and so its label
+	densified = Densify(polygon, 0.1)
+	for _, ring := range densified {
+		for n := 0; n < len(ring)-1; n++ {
+			if l := math.Hypot(ring[n+1][0]-ring[n][0], ring[n+1][1]-ring[n][1]); l > 0.1+1e-12 {
+				t.Errorf("Received a segment of length %v, expected at most 0.1", l)
+			}
+		}
+	}
+	a := PolylabelVerbose(polygon, 0.01)
+	b := PolylabelVerbose(densified, 0.01)
+	if math.Abs(a.Distance-b.Distance) > 1e-9 {
+		t.Errorf("Received %v, expected %v", b.Distance, a.Distance)
+	}
+
+	// the input is not modified or shared
+	copied := Densify(polygon, 0)
+	if !reflect.DeepEqual(copied, polygon) {
+		t.Errorf("Received %v, expected %v", copied, polygon)
+	}
+	copied[0][0] = Coord{5, 5}
+	AssertEqual(t, polygon[0][0], Coord{0, 0})
+}
@@ -0,0 +1,30 @@
+package polylabel
+
+// DistanceField samples the signed distance from the polygon outline, as used
+// by the search, on a gridSize by gridSize grid of cells covering the bounding
+// box. field[i][j] is the distance at the center of the cell in row i and
+// column j, which is (minX+(j+0.5)*cellW, minY+(i+0.5)*cellH). Distances are
+// negative outside the polygon. This is useful for visualizing why a label was
+// placed where it was.
+func DistanceField(polygon Polygon, gridSize int, opts ...Option) (field [][]float64, minX float64, minY float64, cellW float64, cellH float64) {
+	o := newOptions(opts)
+	polygon = closeRings(polygon)
+	minX, minY, maxX, maxY := o.boundingBox(polygon)
+	if gridSize <= 0 {
+		return nil, minX, minY, 0, 0
+	}
+	cellW = (maxX - minX) / float64(gridSize)
+	cellH = (maxY - minY) / float64(gridSize)
+
+	distance := o.distance(polygon)
+	field = make([][]float64, gridSize)
+	for i := range field {
+		y := minY + (float64(i)+0.5)*cellH
+		row := make([]float64, gridSize)
+		for j := range row {
+			row[j] = distance(minX+(float64(j)+0.5)*cellW, y)
+		}
+		field[i] = row
+	}
+	return field, minX, minY, cellW, cellH
+}
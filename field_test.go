@@ -0,0 +1,38 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDistanceField(t *testing.T) {
+	// an L shape with the top right quarter of its bounding box missing
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 2}, Coord{2, 2}, Coord{2, 4}, Coord{0, 4}, Coord{0, 0}}}
+	field, minX, minY, cellW, cellH := DistanceField(polygon, 2)
+	expected := [][]float64{
+		{1, 1},
+		{1, -1},
+	}
+	if !reflect.DeepEqual(field, expected) {
+		t.Errorf("Received %v, expected %v", field, expected)
+	}
+	AssertEqual(t, minX, 0.0)
+	AssertEqual(t, minY, 0.0)
+	AssertEqual(t, cellW, 2.0)
+	AssertEqual(t, cellH, 2.0)
+
+	field, _, _, _, _ = DistanceField(polygon, 0)
+	AssertEqual(t, len(field), 0)
+}
+
+func TestDistanceFieldOpenRing(t *testing.T) {
+	// a ring that does not repeat its first coordinate is closed, as it is by
+	// Polylabel
+	open := Polygon{Ring{Coord{4, 2}, Coord{2, 2}, Coord{2, 4}, Coord{0, 4}, Coord{0, 0}, Coord{4, 0}}}
+	closed := Polygon{append(append(Ring(nil), open[0]...), open[0][0])}
+	field, _, _, _, _ := DistanceField(open, 8)
+	expected, _, _, _, _ := DistanceField(closed, 8)
+	if !reflect.DeepEqual(field, expected) {
+		t.Errorf("Received %v, expected %v", field, expected)
+	}
+}
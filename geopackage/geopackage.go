@@ -0,0 +1,150 @@
+// Package geopackage labels the polygon features of a layer of an OGC
+// GeoPackage.
+//
+// A GeoPackage is a SQLite database, read here through database/sql so that
+// no SQLite driver is imposed: open the file with any registered driver, such
+// as github.com/mattn/go-sqlite3 or modernc.org/sqlite, and pass the
+// resulting *sql.DB. Features are identified by their feature id, the integer
+// primary key of the layer table.
+package geopackage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	polylabel "github.com/snorfalorpagus/polylabel-go"
+)
+
+// Label is the label of a single feature.
+type Label struct {
+	// FID is the feature id, the primary key of the feature in its layer.
+	FID      int64
+	X        float64
+	Y        float64
+	Distance float64
+}
+
+// the sizes of the envelopes of a geometry header, by envelope indicator
+var envelopeSizes = [...]int{0, 32, 48, 48, 64}
+
+// DecodeGeometry decodes a GeoPackage geometry blob holding a Polygon or
+// MultiPolygon, returning its polygons as for polylabel.ParseWKB. An empty
+// geometry gives no polygons.
+func DecodeGeometry(blob []byte) ([]polylabel.Polygon, error) {
+	if len(blob) < 8 || blob[0] != 'G' || blob[1] != 'P' {
+		return nil, errors.New("geopackage: not a GeoPackage geometry")
+	}
+	if blob[2] != 0 {
+		return nil, fmt.Errorf("geopackage: unsupported geometry version %d", blob[2])
+	}
+	flags := blob[3]
+	if flags&0x20 != 0 {
+		return nil, errors.New("geopackage: extended geometry types are not supported")
+	}
+	if flags&0x10 != 0 {
+		return nil, nil
+	}
+	envelope := int(flags>>1) & 0x07
+	if envelope >= len(envelopeSizes) {
+		return nil, fmt.Errorf("geopackage: invalid envelope indicator %d", envelope)
+	}
+	start := 8 + envelopeSizes[envelope]
+	if len(blob) < start {
+		return nil, errors.New("geopackage: truncated geometry header")
+	}
+	return polylabel.ParseWKB(blob[start:])
+}
+
+// LabelLayer labels each polygon feature of the named layer of the
+// GeoPackage open as db. For features holding several polygons the label
+// with the greatest distance is used. Features with a null or empty geometry
+// are skipped.
+func LabelLayer(db *sql.DB, layer string, precision float64, opts ...polylabel.Option) ([]Label, error) {
+	var column string
+	err := db.QueryRow("SELECT column_name FROM gpkg_geometry_columns WHERE table_name = ?", layer).Scan(&column)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("geopackage: no geometry column for layer %q", layer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT rowid, %s FROM %s", quoteIdentifier(column), quoteIdentifier(layer)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labeler := polylabel.NewLabeler(opts...)
+	var labels []Label
+	for rows.Next() {
+		var fid int64
+		var blob []byte
+		if err := rows.Scan(&fid, &blob); err != nil {
+			return nil, err
+		}
+		if blob == nil {
+			continue
+		}
+		polygons, err := DecodeGeometry(blob)
+		if err != nil {
+			return nil, fmt.Errorf("geopackage: feature %d: %v", fid, err)
+		}
+		label := Label{FID: fid, Distance: math.Inf(-1)}
+		for _, polygon := range polygons {
+			if len(polygon) == 0 || len(polygon[0]) == 0 {
+				continue
+			}
+			result := labeler.Label(polygon, precision)
+			if result.Distance > label.Distance {
+				label.X, label.Y, label.Distance = result.X, result.Y, result.Distance
+			}
+		}
+		if !math.IsInf(label.Distance, -1) {
+			labels = append(labels, label)
+		}
+	}
+	return labels, rows.Err()
+}
+
+// quote a table or column name for SQLite
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         int64                  `json:"id"`
+	Geometry   geoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// WriteGeoJSON writes labels as a GeoJSON FeatureCollection of points, with
+// the feature id as the id of each feature and the distance as a property.
+func WriteGeoJSON(w io.Writer, labels []Label) error {
+	features := make([]geoJSONFeature, len(labels))
+	for i, label := range labels {
+		features[i] = geoJSONFeature{
+			Type:     "Feature",
+			ID:       label.FID,
+			Geometry: geoJSONPoint{"Point", [2]float64{label.X, label.Y}},
+			Properties: map[string]interface{}{
+				"distance": label.Distance,
+			},
+		}
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+}
@@ -0,0 +1,190 @@
+package geopackage
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	polylabel "github.com/snorfalorpagus/polylabel-go"
+)
+
+// encode polygons as a GeoPackage geometry with an envelope, as a Polygon
+// for one polygon and a MultiPolygon otherwise
+func buildGeometry(polygons ...polylabel.Polygon) []byte {
+	var b bytes.Buffer
+	le := func(v interface{}) { binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("GP")
+	b.WriteByte(0)
+	b.WriteByte(1 | 1<<1) // little endian with an xy envelope
+	le(int32(4326))
+	le([4]float64{})
+	polygon := func(p polylabel.Polygon) {
+		b.WriteByte(1)
+		le(uint32(3))
+		le(uint32(len(p)))
+		for _, ring := range p {
+			le(uint32(len(ring)))
+			le(ring)
+		}
+	}
+	if len(polygons) == 1 {
+		polygon(polygons[0])
+		return b.Bytes()
+	}
+	b.WriteByte(1)
+	le(uint32(6))
+	le(uint32(len(polygons)))
+	for _, p := range polygons {
+		polygon(p)
+	}
+	return b.Bytes()
+}
+
+func TestDecodeGeometry(t *testing.T) {
+	square := polylabel.Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}
+	polygons, err := DecodeGeometry(buildGeometry(square))
+	if err != nil || !reflect.DeepEqual(polygons, []polylabel.Polygon{square}) {
+		t.Errorf("Received %v, %v, expected %v", polygons, err, square)
+	}
+
+	empty := []byte{'G', 'P', 0, 1 | 0x10, 0, 0, 0, 0}
+	polygons, err = DecodeGeometry(empty)
+	if err != nil || polygons != nil {
+		t.Errorf("Received %v, %v, expected no polygons", polygons, err)
+	}
+
+	for _, test := range []struct {
+		blob     []byte
+		expected string
+	}{
+		{[]byte("GP"), "geopackage: not a GeoPackage geometry"},
+		{[]byte{'G', 'P', 1, 1, 0, 0, 0, 0}, "geopackage: unsupported geometry version 1"},
+		{[]byte{'G', 'P', 0, 0x20, 0, 0, 0, 0}, "geopackage: extended geometry types are not supported"},
+		{[]byte{'G', 'P', 0, 5 << 1, 0, 0, 0, 0}, "geopackage: invalid envelope indicator 5"},
+		{[]byte{'G', 'P', 0, 1 << 1, 0, 0, 0, 0}, "geopackage: truncated geometry header"},
+		{[]byte{'G', 'P', 0, 1, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, "polylabel: invalid WKB at offset 5: unsupported geometry type 1"},
+	} {
+		if _, err := DecodeGeometry(test.blob); err == nil || err.Error() != test.expected {
+			t.Errorf("Received %v, expected %v", err, test.expected)
+		}
+	}
+}
+
+// a database/sql driver answering the two queries of LabelLayer from fixed
+// rows, standing in for a SQLite driver
+type fakeDriver struct {
+	column   string
+	features [][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	switch {
+	case strings.Contains(s.query, "gpkg_geometry_columns"):
+		if args[0] != "parcels" {
+			return &fakeRows{columns: []string{"column_name"}}, nil
+		}
+		return &fakeRows{columns: []string{"column_name"}, rows: [][]driver.Value{{s.d.column}}}, nil
+	case s.query == `SELECT rowid, "ge""om" FROM "parcels"`:
+		return &fakeRows{columns: []string{"rowid", s.d.column}, rows: s.d.features}, nil
+	}
+	return nil, errors.New("unexpected query " + s.query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestLabelLayer(t *testing.T) {
+	square := polylabel.Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}
+	big := polylabel.Polygon{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}}
+	sql.Register("fakegpkg", &fakeDriver{
+		column: `ge"om`,
+		features: [][]driver.Value{
+			{int64(1), buildGeometry(square)},
+			{int64(2), nil},
+			{int64(5), buildGeometry(square, big)},
+			{int64(6), []byte{'G', 'P', 0, 1 | 0x10, 0, 0, 0, 0}},
+		},
+	})
+	db, err := sql.Open("fakegpkg", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	labels, err := LabelLayer(db, "parcels", 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Label{{FID: 1, X: 2, Y: 2, Distance: 2}, {FID: 5, X: 15, Y: 5, Distance: 5}}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Received %v, expected %v", labels, expected)
+	}
+
+	if _, err := LabelLayer(db, "roads", 0.01); err == nil || err.Error() != `geopackage: no geometry column for layer "roads"` {
+		t.Errorf("Received %v, expected no geometry column", err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteGeoJSON(&out, labels); err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Features []struct {
+			ID       int64
+			Geometry struct {
+				Coordinates [2]float64
+			}
+			Properties map[string]float64
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	assertFeature := func(i int, fid int64, x, y, distance float64) {
+		f := collection.Features[i]
+		if f.ID != fid || f.Geometry.Coordinates != [2]float64{x, y} || f.Properties["distance"] != distance {
+			t.Errorf("Received %+v, expected feature %d at %v, %v", f, fid, x, y)
+		}
+	}
+	assertFeature(0, 1, 2, 2, 2)
+	assertFeature(1, 5, 15, 5, 5)
+}
@@ -0,0 +1,38 @@
+package polylabel
+
+import "math"
+
+// DistanceGradient returns the gradient of the signed distance from the
+// polygon outline at x, y, which is positive inside the polygon. It is a unit
+// vector pointing directly away from the nearest point of the outline when
+// x, y is inside, so its negation points at the nearest boundary, and towards
+// the nearest point when outside. The gradient is zero on the outline and
+// for an empty polygon.
+//
+// A label found by Polylabel is usually equidistant from several points of
+// the outline, where the distance has no single gradient; the gradient for
+// the first of the nearest segments is returned.
+func DistanceGradient(x float64, y float64, polygon Polygon) (dx float64, dy float64) {
+	polygon = closeRings(polygon)
+	minDistSq := math.Inf(1)
+	var nearest Coord
+	for _, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			px, py := segmentClosestPoint(x, y, ring[n], ring[n+1])
+			if distSq := squaredDistance(x, y, Coord{px, py}); distSq < minDistSq {
+				minDistSq = distSq
+				nearest = Coord{px, py}
+			}
+		}
+	}
+	if !(minDistSq > 0) || math.IsInf(minDistSq, 1) {
+		return 0, 0
+	}
+
+	dist := math.Hypot(x-nearest[0], y-nearest[1])
+	dx, dy = (x-nearest[0])/dist, (y-nearest[1])/dist
+	if pointToPolygonDistance(x, y, polygon) < 0 {
+		dx, dy = -dx, -dy
+	}
+	return dx, dy
+}
@@ -0,0 +1,38 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceGradient(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{0, 4}, Coord{0, 0}}}
+	for _, c := range []struct {
+		x, y     float64
+		expected Coord
+	}{
+		{5, 1, Coord{0, 1}},        // nearest the bottom edge, inside
+		{9, 2, Coord{-1, 0}},       // nearest the right edge, inside
+		{5, -3, Coord{0, 1}},       // below the bottom edge, outside
+		{13, 8, Coord{-0.6, -0.8}}, // beyond the top right corner
+		{5, 0, Coord{0, 0}},        // on the outline
+	} {
+		dx, dy := DistanceGradient(c.x, c.y, polygon)
+		if math.Abs(dx-c.expected[0]) > 1e-12 || math.Abs(dy-c.expected[1]) > 1e-12 {
+			t.Errorf("Received %v, %v at %v, %v, expected %v", dx, dy, c.x, c.y, c.expected)
+		}
+	}
+
+	// the gradient agrees with finite differences away from the medial axis
+	water := loadData("test_data/water1.json")
+	x, y, h := 3800.0, 2100.0, 1e-3
+	dx, dy := DistanceGradient(x, y, water)
+	gx := (pointToPolygonDistance(x+h, y, water) - pointToPolygonDistance(x-h, y, water)) / (2 * h)
+	gy := (pointToPolygonDistance(x, y+h, water) - pointToPolygonDistance(x, y-h, water)) / (2 * h)
+	if math.Abs(dx-gx) > 1e-6 || math.Abs(dy-gy) > 1e-6 {
+		t.Errorf("Received %v, %v, expected %v, %v", dx, dy, gx, gy)
+	}
+
+	dx, dy = DistanceGradient(1, 1, Polygon{})
+	AssertEqual(t, Coord{dx, dy}, Coord{0, 0})
+}
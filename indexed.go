@@ -0,0 +1,60 @@
+package polylabel
+
+import (
+	"fmt"
+	"math"
+)
+
+// PolylabelIndexed labels a polygon whose rings are lists of indices into a
+// pool of coordinates shared with other polygons, as in topological data
+// where neighbouring polygons share their vertices. Coordinates are read from
+// the pool as distances are evaluated, so the polygon is never copied out of
+// it apart from its exterior ring, which is copied once. The result is the
+// same as labelling the equivalent Polygon, with the options limited as for
+// PolylabelSource. It is an error if an index is outside the pool.
+func PolylabelIndexed(pool []Coord, rings [][]int, precision float64, opts ...Option) (Result, error) {
+	for i, ring := range rings {
+		for _, index := range ring {
+			if index < 0 || index >= len(pool) {
+				return Result{}, fmt.Errorf("polylabel: ring %d has index %d outside a pool of %d coordinates", i, index, len(pool))
+			}
+		}
+	}
+	var exterior Ring
+	if len(rings) > 0 {
+		exterior = make(Ring, len(rings[0]))
+		for n, index := range rings[0] {
+			exterior[n] = pool[index]
+		}
+	}
+	bounds, cx, cy := ringsExtent(closeRings(Polygon{exterior}))
+	return labelDistance(bounds, cx, cy, func(x, y float64) float64 {
+		return pointToIndexedDistance(x, y, pool, rings)
+	}, precision, opts), nil
+}
+
+// signed distance from point to the outline of a polygon whose rings index
+// into pool (negative if point is outside), as for pointToPolygonDistance;
+// each ring is closed by an edge from its last index back to its first, which
+// has no length if the ring is already closed
+func pointToIndexedDistance(x float64, y float64, pool []Coord, rings [][]int) float64 {
+	inside := false
+	minDistSq := math.Inf(1)
+
+	for _, ring := range rings {
+		for n := range ring {
+			a := pool[ring[n]]
+			b := pool[ring[(n+1)%len(ring)]]
+			if rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
+		}
+	}
+
+	factor := 1.0
+	if !inside {
+		factor = -1.0
+	}
+	return factor * math.Sqrt(minDistSq)
+}
@@ -0,0 +1,54 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelIndexed(t *testing.T) {
+	// two squares sharing their middle edge, with a hole in the left one
+	pool := []Coord{
+		{0, 0}, {4, 0}, {8, 0}, {8, 4}, {4, 4}, {0, 4},
+		{1, 1}, {1, 3}, {3, 3}, {3, 1},
+	}
+	left := [][]int{{0, 1, 4, 5, 0}, {6, 7, 8, 9, 6}}
+	right := [][]int{{1, 2, 3, 4, 1}}
+
+	for _, rings := range [][][]int{left, right} {
+		polygon := make(Polygon, len(rings))
+		for i, ring := range rings {
+			for _, index := range ring {
+				polygon[i] = append(polygon[i], pool[index])
+			}
+		}
+		expected := PolylabelVerbose(polygon, 0.01)
+		result, err := PolylabelIndexed(pool, rings, 0.01)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v, expected %v", result, expected)
+		}
+	}
+
+	if _, err := PolylabelIndexed(pool, [][]int{{0, 1, 10, 0}}, 0.01); err == nil {
+		t.Error("Expected an error for an index outside the pool")
+	}
+	if _, err := PolylabelIndexed(pool, [][]int{{0, -1, 4, 0}}, 0.01); err == nil {
+		t.Error("Expected an error for a negative index")
+	}
+}
+
+func TestPolylabelIndexedOpenRing(t *testing.T) {
+	// a ring that does not repeat its first index is closed, as it is by
+	// Polylabel
+	pool := []Coord{{0, 0}, {10, 0}, {0, 10}}
+	expected := PolylabelVerbose(Polygon{Ring{pool[0], pool[1], pool[2]}}, 0.01)
+	result, err := PolylabelIndexed(pool, [][]int{{0, 1, 2}}, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
@@ -0,0 +1,45 @@
+package polylabel
+
+import "math"
+
+// LargestInscribedSquare returns an axis-aligned square that fits inside
+// polygon, for fitting a block of label text. It is the square inscribed in
+// the circle found by PolylabelVerbose, so its side is the circle radius
+// times sqrt2 and its center is the pole of inaccessibility. The square is
+// only approximately the largest: a square against the outline may be larger
+// than one inside the circle. The half size is zero if no point inside the
+// polygon was found.
+func LargestInscribedSquare(polygon Polygon, precision float64, opts ...Option) (center Coord, halfSize float64) {
+	result := PolylabelVerbose(polygon, precision, opts...)
+	if result.Distance > 0 {
+		halfSize = result.Distance / math.Sqrt2
+	}
+	return Coord{result.X, result.Y}, halfSize
+}
+
+// InscribedCirclePolygon returns the circle found by PolylabelVerbose, the
+// largest that fits inside polygon to within precision, as a regular polygon
+// of segments sides for drawing, such as a label background. The vertices
+// lie on the circle, starting at its rightmost point and running
+// counterclockwise, so the polygon is inside the circle and the ring is
+// closed. segments is at least 3. The result is nil if no point inside the
+// polygon was found.
+func InscribedCirclePolygon(polygon Polygon, precision float64, segments int, opts ...Option) Polygon {
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return nil
+	}
+	result := PolylabelVerbose(polygon, precision, opts...)
+	if !(result.Distance > 0) {
+		return nil
+	}
+	if segments < 3 {
+		segments = 3
+	}
+	ring := make(Ring, segments+1)
+	for i := 0; i < segments; i++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(segments))
+		ring[i] = Coord{result.X + result.Distance*cos, result.Y + result.Distance*sin}
+	}
+	ring[segments] = ring[0]
+	return Polygon{ring}
+}
@@ -0,0 +1,69 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLargestInscribedSquare(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{8, 0}, Coord{8, 4}, Coord{0, 4}, Coord{0, 0}}}
+	center, halfSize := LargestInscribedSquare(polygon, 0.01)
+	AssertEqual(t, center, Coord{4, 2})
+	if math.Abs(halfSize-2/math.Sqrt2) > 1e-9 {
+		t.Errorf("Received %v, expected %v", halfSize, 2/math.Sqrt2)
+	}
+
+	// every corner of the square is inside the polygon
+	polygon = loadData("test_data/water1.json")
+	center, halfSize = LargestInscribedSquare(polygon, 1.0)
+	for _, corner := range []Coord{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		x := center[0] + corner[0]*halfSize
+		y := center[1] + corner[1]*halfSize
+		if d := pointToPolygonDistance(x, y, polygon); d < 0 {
+			t.Errorf("Received corner %v, %v outside the polygon", x, y)
+		}
+	}
+
+	_, halfSize = LargestInscribedSquare(Polygon{Ring{Coord{1, 1}}}, 1.0)
+	AssertEqual(t, halfSize, 0.0)
+}
+
+func TestInscribedCirclePolygon(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{8, 0}, Coord{8, 4}, Coord{0, 4}, Coord{0, 0}}}
+	circle := InscribedCirclePolygon(polygon, 0.01, 4)
+	AssertEqual(t, len(circle), 1)
+	AssertEqual(t, len(circle[0]), 5)
+	expected := Ring{Coord{6, 2}, Coord{4, 4}, Coord{2, 2}, Coord{4, 0}, Coord{6, 2}}
+	for i, c := range circle[0] {
+		if math.Abs(c[0]-expected[i][0]) > 1e-9 || math.Abs(c[1]-expected[i][1]) > 1e-9 {
+			t.Errorf("Received %v, expected %v", circle[0], expected)
+			break
+		}
+	}
+	AssertEqual(t, ringArea(circle[0]) > 0, true)
+
+	// every vertex is inside the polygon and on the circle
+	polygon = loadData("test_data/water1.json")
+	result := PolylabelVerbose(polygon, 1.0)
+	circle = InscribedCirclePolygon(polygon, 1.0, 64)
+	AssertEqual(t, len(circle[0]), 65)
+	AssertEqual(t, circle[0][64], circle[0][0])
+	for _, c := range circle[0] {
+		if d := pointToPolygonDistance(c[0], c[1], polygon); d < -1e-9 {
+			t.Errorf("Received vertex %v outside the polygon", c)
+		}
+		if r := math.Hypot(c[0]-result.X, c[1]-result.Y); math.Abs(r-result.Distance) > 1e-9 {
+			t.Errorf("Received vertex %v at %v from the center, expected %v", c, r, result.Distance)
+		}
+	}
+
+	// too few segments make a triangle
+	AssertEqual(t, len(InscribedCirclePolygon(polygon, 1.0, 1)[0]), 4)
+
+	if circle := InscribedCirclePolygon(Polygon{Ring{Coord{1, 1}}}, 1.0, 8); circle != nil {
+		t.Errorf("Received %v, expected nil", circle)
+	}
+	if circle := InscribedCirclePolygon(Polygon{}, 1.0, 8); circle != nil {
+		t.Errorf("Received %v, expected nil", circle)
+	}
+}
@@ -0,0 +1,190 @@
+package polylabel
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// the largest coordinate accepted by PolylabelInteger, which keeps every
+// product of fixed point differences within an int64
+const maxIntegerCoordinate = 1 << 20
+
+// fractional bits of the fixed point coordinates of PolylabelInteger
+const integerFractionBits = 8
+
+// PolylabelInteger is like PolylabelVerbose for polygons whose coordinates
+// are all integers of magnitude at most 2^20, such as those of vector tiles,
+// and gives the same result on every architecture. Cell centers are held in
+// fixed point with 8 fractional bits and the squared distances to the
+// outline are compared exactly in integers, leaving floating point for the
+// square root of the nearest. Go allows a multiply and add to be fused on
+// some architectures, which can otherwise change the result in the last bit
+// and so the label. The search stops at cells of 1/256 of a unit even if
+// precision is finer. The options are limited as for PolylabelSource. It is
+// an error if a coordinate is not such an integer.
+func PolylabelInteger(polygon Polygon, precision float64, opts ...Option) (Result, error) {
+	polygon = closeRings(polygon)
+	rings := make([][][2]int64, len(polygon))
+	for i, ring := range polygon {
+		rings[i] = make([][2]int64, len(ring))
+		for n, coord := range ring {
+			for _, v := range coord {
+				if v != math.Trunc(v) || math.Abs(v) > maxIntegerCoordinate {
+					return Result{}, fmt.Errorf("polylabel: coordinate %v of ring %d is not an integer of magnitude at most 2^20", v, i)
+				}
+			}
+			rings[i][n] = [2]int64{int64(coord[0]) << integerFractionBits, int64(coord[1]) << integerFractionBits}
+		}
+	}
+
+	o := newOptions(opts)
+	o.withoutPolygon()
+	// a guess need not be on the grid of cell centers
+	o.initialGuess = false
+	o.grid = 1.0 / (1 << integerFractionBits)
+	s := &Search{precision: precision, o: o}
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return s.Result(), nil
+	}
+	s.minX, s.minY, s.maxX, s.maxY = boundingBox(polygon)
+	s.cellSize = math.Min(s.maxX-s.minX, s.maxY-s.minY)
+	s.precision = o.bracketPrecision(precision, s.cellSize)
+	if s.cellSize > 0 {
+		s.distance = func(x, y float64) float64 {
+			// cell centers are multiples of the grid, so these are exact
+			px := int64(x * (1 << integerFractionBits))
+			py := int64(y * (1 << integerFractionBits))
+			d := pointToFixedDistance(px, py, rings)
+			if o.viewport {
+				d = math.Min(d, o.viewportDistance(x, y))
+			}
+			return d
+		}
+		bound := o.bound()
+		var cellAt cellFunc = func(x, y, h float64) cell {
+			d := s.distance(x, y)
+			// the explicit conversion keeps the multiply from being fused
+			return cell{x, y, h, d, d + float64(h*bound)}
+		}
+		if o.compactness > 0 {
+			cellAt = compactCells(cellAt, s.distance, o.compactness)
+		}
+
+		// cover a square whose side is a power of two, so that every cell
+		// center down to cells of the grid size is on the grid; the centroid
+		// is not, so the bounding box center stands in for it
+		cx, cy := (s.minX+s.maxX)/2, (s.minY+s.maxY)/2
+		maxX, maxY := s.maxX, s.maxY
+		side := math.Exp2(math.Ceil(math.Log2(math.Max(maxX-s.minX, maxY-s.minY))))
+		s.maxX, s.maxY = s.minX+side, s.minY+side
+		s.seed(cellAt, cx, cy, nil)
+		s.maxX, s.maxY = maxX, maxY
+	}
+	s.Run(0)
+	return s.Result(), nil
+}
+
+// a squared distance as the fraction num/den, with a 128-bit numerator
+type exactDistSq struct {
+	hi  uint64
+	lo  uint64
+	den uint64
+}
+
+// report whether a is less than b, comparing the 192-bit cross products
+func (a exactDistSq) less(b exactDistSq) bool {
+	a2, a1, a0 := mul128(a.hi, a.lo, b.den)
+	b2, b1, b0 := mul128(b.hi, b.lo, a.den)
+	if a2 != b2 {
+		return a2 < b2
+	}
+	if a1 != b1 {
+		return a1 < b1
+	}
+	return a0 < b0
+}
+
+// multiply the 128-bit hi, lo by m
+func mul128(hi uint64, lo uint64, m uint64) (r2 uint64, r1 uint64, r0 uint64) {
+	h1, r0 := bits.Mul64(lo, m)
+	h2, l2 := bits.Mul64(hi, m)
+	r1, carry := bits.Add64(h1, l2, 0)
+	return h2 + carry, r1, r0
+}
+
+// the value of a squared distance in units of the input coordinates; every
+// step is correctly rounded, and the product is exact so cannot be changed
+// by fusing it with the sum
+func (a exactDistSq) distance() float64 {
+	num := float64(a.hi)*0x1p64 + float64(a.lo)
+	return math.Sqrt(num/float64(a.den)) / (1 << integerFractionBits)
+}
+
+// signed distance from a fixed point to the outline of a fixed point polygon
+// (negative if point is outside), as for pointToPolygonDistance
+func pointToFixedDistance(x int64, y int64, rings [][][2]int64) float64 {
+	inside := false
+	var minDistSq exactDistSq
+	found := false
+
+	for _, ring := range rings {
+		for n := 0; n < (len(ring) - 1); n++ {
+			a := ring[n]
+			b := ring[n+1]
+			if fixedRayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			if distSq := fixedSegmentDistanceSquared(x, y, a, b); !found || distSq.less(minDistSq) {
+				minDistSq = distSq
+				found = true
+			}
+		}
+	}
+	if !found {
+		return math.Inf(-1)
+	}
+
+	factor := 1.0
+	if !inside {
+		factor = -1.0
+	}
+	return factor * minDistSq.distance()
+}
+
+// whether a ray cast from a point in the +x direction crosses a segment, as
+// for rayCrosses but without division
+func fixedRayCrosses(x int64, y int64, a [2]int64, b [2]int64) bool {
+	if (a[1] > y) == (b[1] > y) {
+		return false
+	}
+	lhs := (x - a[0]) * (b[1] - a[1])
+	rhs := (b[0] - a[0]) * (y - a[1])
+	if b[1] > a[1] {
+		return lhs < rhs
+	}
+	return lhs > rhs
+}
+
+// get the exact squared distance from a point to a segment
+func fixedSegmentDistanceSquared(px int64, py int64, a [2]int64, b [2]int64) exactDistSq {
+	ex, ey := b[0]-a[0], b[1]-a[1]
+	dx, dy := px-a[0], py-a[1]
+	dot := dx*ex + dy*ey
+	length := ex*ex + ey*ey
+	if length == 0 || dot <= 0 {
+		return exactDistSq{lo: uint64(dx*dx + dy*dy), den: 1}
+	}
+	if dot >= length {
+		dx, dy = px-b[0], py-b[1]
+		return exactDistSq{lo: uint64(dx*dx + dy*dy), den: 1}
+	}
+
+	// the distance from the line is the cross product over the length
+	cross := dx*ey - dy*ex
+	if cross < 0 {
+		cross = -cross
+	}
+	hi, lo := bits.Mul64(uint64(cross), uint64(cross))
+	return exactDistSq{hi: hi, lo: lo, den: uint64(length)}
+}
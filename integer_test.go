@@ -0,0 +1,76 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolylabelInteger(t *testing.T) {
+	for _, filename := range []string{"test_data/water1.json", "test_data/water2.json"} {
+		polygon := loadData(filename)
+		expected := PolylabelVerbose(polygon, 1.0)
+		result, err := PolylabelInteger(polygon, 1.0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(result.Distance-expected.Distance) > 1.0 {
+			t.Errorf("Received distance %v, expected within 1 of %v", result.Distance, expected.Distance)
+		}
+		if d := pointToPolygonDistance(result.X, result.Y, polygon); math.Abs(d-result.Distance) > 1e-9 {
+			t.Errorf("Received distance %v, expected %v at the label", result.Distance, d)
+		}
+	}
+
+	// exact values, which must not vary between architectures
+	result, _ := PolylabelInteger(loadData("test_data/water1.json"), 1.0)
+	AssertEqual(t, result.X, 3866.0)
+	AssertEqual(t, result.Y, 2125.0)
+	AssertEqual(t, result.Distance, 288.9429009337312)
+
+	for _, polygon := range []Polygon{
+		{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4.5}, Coord{0, 0}}},
+		{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 1 << 21}, Coord{0, 0}}},
+		{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, math.NaN()}, Coord{0, 0}}},
+	} {
+		if _, err := PolylabelInteger(polygon, 1.0); err == nil {
+			t.Errorf("Expected an error for %v", polygon)
+		}
+	}
+	result, err := PolylabelInteger(Polygon{}, 1.0)
+	if err != nil || result.X != 0 || result.Y != 0 {
+		t.Errorf("Received %v, %v for an empty polygon", result, err)
+	}
+}
+
+func TestPointToFixedDistance(t *testing.T) {
+	polygon := loadData("test_data/water2.json")
+	rings := make([][][2]int64, len(polygon))
+	for i, ring := range polygon {
+		for _, coord := range ring {
+			rings[i] = append(rings[i], [2]int64{int64(coord[0]) << integerFractionBits, int64(coord[1]) << integerFractionBits})
+		}
+	}
+
+	// the exact distance agrees with the float one to rounding, including
+	// on the vertices and on the outline
+	for x := -200.0; x < 4300; x += 97.25 {
+		for y := 0.0; y < 4300; y += 101.5 {
+			expected := pointToPolygonDistance(x, y, polygon)
+			d := pointToFixedDistance(int64(x*(1<<integerFractionBits)), int64(y*(1<<integerFractionBits)), rings)
+			if math.Abs(d-expected) > 1e-9*math.Max(1, math.Abs(expected)) {
+				t.Errorf("Received %v at %v, %v, expected %v", d, x, y, expected)
+			}
+		}
+	}
+	for _, coord := range polygon[0][:10] {
+		d := pointToFixedDistance(int64(coord[0])<<integerFractionBits, int64(coord[1])<<integerFractionBits, rings)
+		AssertEqual(t, math.Abs(d), 0.0)
+	}
+
+	// fractions too large for an int64 once cross multiplied
+	big := exactDistSq{hi: 1, lo: 0, den: 3}
+	bigger := exactDistSq{hi: 1, lo: 1, den: 3}
+	AssertEqual(t, big.less(bigger), true)
+	AssertEqual(t, bigger.less(big), false)
+	AssertEqual(t, big.less(exactDistSq{hi: 2, den: 7}), false)
+}
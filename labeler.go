@@ -0,0 +1,59 @@
+package polylabel
+
+// Labeler labels polygons with a fixed set of options, reusing its internal
+// buffers from one call to the next to reduce allocations when labeling many
+// polygons. A Labeler is not safe for concurrent use; use a LabelerPool to
+// share labelers between goroutines.
+type Labeler struct {
+	o     *options
+	queue cellQueue
+}
+
+// NewLabeler returns a Labeler that applies opts to every polygon it labels.
+func NewLabeler(opts ...Option) *Labeler {
+	return &Labeler{o: newOptions(opts)}
+}
+
+// Label is like PolylabelVerbose with the options of the Labeler.
+func (l *Labeler) Label(polygon Polygon, precision float64) Result {
+	s := startSearch(polygon, precision, l.o, l.queue)
+	s.Run(0)
+	if s.cells != nil {
+		l.queue = s.cells.queue
+	}
+	return s.Result()
+}
+
+// LabelerPool hands out Labelers to goroutines so that each has its own
+// buffers. A LabelerPool is safe for concurrent use.
+type LabelerPool struct {
+	labelers chan *Labeler
+}
+
+// NewLabelerPool returns a pool of n Labelers with the given options. At most
+// n polygons are labeled through the pool at once.
+func NewLabelerPool(n int, opts ...Option) *LabelerPool {
+	p := &LabelerPool{labelers: make(chan *Labeler, n)}
+	for i := 0; i < n; i++ {
+		p.labelers <- NewLabeler(opts...)
+	}
+	return p
+}
+
+// Get takes a Labeler from the pool, waiting for one to be returned if all are
+// in use. The Labeler must be given back with Put once finished with.
+func (p *LabelerPool) Get() *Labeler {
+	return <-p.labelers
+}
+
+// Put returns a Labeler taken with Get to the pool.
+func (p *LabelerPool) Put(l *Labeler) {
+	p.labelers <- l
+}
+
+// Label labels polygon with a Labeler from the pool.
+func (p *LabelerPool) Label(polygon Polygon, precision float64) Result {
+	l := p.Get()
+	defer p.Put(l)
+	return l.Label(polygon, precision)
+}
@@ -0,0 +1,48 @@
+package polylabel
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLabeler(t *testing.T) {
+	polygons := []Polygon{
+		loadData("test_data/water1.json"),
+		loadData("test_data/water2.json"),
+		loadData("test_data/water1.json"),
+	}
+	labeler := NewLabeler(WithTangentPoints(true))
+	for _, polygon := range polygons {
+		expected := PolylabelVerbose(polygon, 1.0, WithTangentPoints(true))
+		result := labeler.Label(polygon, 1.0)
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v, expected %v", result, expected)
+		}
+	}
+}
+
+func TestLabelerPool(t *testing.T) {
+	polygons := []Polygon{
+		loadData("test_data/water1.json"),
+		loadData("test_data/water2.json"),
+	}
+	expected := make([]Result, len(polygons))
+	for i, polygon := range polygons {
+		expected[i] = PolylabelVerbose(polygon, 1.0)
+	}
+
+	pool := NewLabelerPool(2)
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result := pool.Label(polygons[i], 1.0)
+			if !reflect.DeepEqual(result, expected[i]) {
+				t.Errorf("Received %v, expected %v", result, expected[i])
+			}
+		}(n % len(polygons))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+package polylabel
+
+import (
+	"math"
+	"sort"
+)
+
+// PolylabelMulti returns the label of polygon at each of precisions, in the
+// same order, from a single search run to the finest precision. The result
+// for a coarser precision is recorded as soon as no cell left in the queue
+// could improve on the best distance by more than that precision, so each
+// result is within its precision of the pole, as with PolylabelVerbose. It is
+// useful for computing labels for several zoom levels at once.
+func PolylabelMulti(polygon Polygon, precisions []float64, opts ...Option) []Result {
+	results := make([]Result, len(precisions))
+	if len(precisions) == 0 {
+		return results
+	}
+
+	// visit the precisions from coarsest to finest
+	order := make([]int, len(precisions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return precisions[order[i]] > precisions[order[j]]
+	})
+
+	s := NewSearch(polygon, precisions[order[len(order)-1]], opts...)
+	next := 0
+	for {
+		gap := s.gap()
+		for next < len(order) && gap <= precisions[order[next]] {
+			results[order[next]] = s.result(precisions[order[next]])
+			next++
+		}
+		if next == len(order) || !s.cells.step() {
+			break
+		}
+	}
+	// only reached if the search ran out of cells with a NaN bound
+	for ; next < len(order); next++ {
+		results[order[next]] = s.result(precisions[order[next]])
+	}
+	return results
+}
+
+// get how much the best distance could still improve by, or -Inf once the
+// search has finished
+func (s *Search) gap() float64 {
+	if s.Done() {
+		return math.Inf(-1)
+	}
+	if !s.o.greedy {
+		return s.cells.queue[0].max - s.cells.best.d
+	}
+	// the greatest max may be anywhere in a queue ordered by distance
+	max := math.Inf(-1)
+	for _, c := range s.cells.queue {
+		max = math.Max(max, c.max)
+	}
+	return max - s.cells.best.d
+}
+
+// PrecisionSweep labels polygon at each of precisions, as PolylabelMulti
+// does in a single search, to show how the label settles as precision is
+// tightened. Pass the precisions from coarsest to finest and measure the
+// movement between them with Displacements.
+func PrecisionSweep(polygon Polygon, precisions []float64, opts ...Option) []Result {
+	return PolylabelMulti(polygon, precisions, opts...)
+}
+
+// Displacements returns how far the label moves between each pair of
+// consecutive results, such as those of PrecisionSweep, and the largest of
+// these moves. Once the moves stay small, tightening precision further no
+// longer matters. Fewer than two results give no moves and a largest of 0.
+func Displacements(results []Result) (series []float64, largest float64) {
+	for i := 1; i < len(results); i++ {
+		d := math.Hypot(results[i].X-results[i-1].X, results[i].Y-results[i-1].Y)
+		series = append(series, d)
+		largest = math.Max(largest, d)
+	}
+	return series, largest
+}
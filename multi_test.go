@@ -0,0 +1,66 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelMulti(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	precisions := []float64{1.0, 50.0, 10.0}
+	results := PolylabelMulti(polygon, precisions)
+	AssertEqual(t, len(results), 3)
+
+	// the finest precision is a complete search
+	if expected := PolylabelVerbose(polygon, 1.0); !reflect.DeepEqual(results[0], expected) {
+		t.Errorf("Received %v, expected %v", results[0], expected)
+	}
+
+	// coarser results are within their precision of the finest
+	for i, precision := range precisions {
+		if results[i].Distance < results[0].Distance-precision {
+			t.Errorf("Received %v at precision %v, expected within %v of %v", results[i].Distance, precision, precision, results[0].Distance)
+		}
+	}
+}
+
+func TestPolylabelMultiDegenerate(t *testing.T) {
+	AssertEqual(t, len(PolylabelMulti(Polygon{}, nil)), 0)
+
+	results := PolylabelMulti(Polygon{Ring{Coord{1, 1}}}, []float64{1, 2})
+	if expected := []Result{{X: 1, Y: 1}, {X: 1, Y: 1}}; !reflect.DeepEqual(results, expected) {
+		t.Errorf("Received %v, expected %v", results, expected)
+	}
+
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	for _, result := range PolylabelMulti(square, []float64{0.1, 10}) {
+		if math.Abs(result.Distance-2) > 1e-9 {
+			t.Errorf("Received %v, expected 2", result.Distance)
+		}
+	}
+}
+
+func TestPrecisionSweep(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	precisions := []float64{100, 10, 1, 0.1}
+	results := PrecisionSweep(polygon, precisions)
+	if expected := PolylabelMulti(polygon, precisions); !reflect.DeepEqual(results, expected) {
+		t.Errorf("Received %v, expected %v", results, expected)
+	}
+
+	series, largest := Displacements(results)
+	AssertEqual(t, len(series), 3)
+	for i, d := range series {
+		expected := math.Hypot(results[i+1].X-results[i].X, results[i+1].Y-results[i].Y)
+		AssertEqual(t, d, expected)
+		if d > largest {
+			t.Errorf("Received %v, expected at most the largest %v", d, largest)
+		}
+	}
+	AssertEqual(t, largest == series[0] || largest == series[1] || largest == series[2], true)
+
+	series, largest = Displacements(results[:1])
+	AssertEqual(t, len(series), 0)
+	AssertEqual(t, largest, 0.0)
+}
@@ -0,0 +1,237 @@
+package polylabel
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// LabelNDJSON reads newline-delimited GeoJSON features from r, labels each
+// and writes a feature for each label to w, also newline delimited, in the
+// order of the input. Each output feature has a Point geometry at the label,
+// the id and properties of its input feature, and a "distance" property
+// holding the distance of the label from the outline, replacing any property
+// of that name. The label of a MultiPolygon is the best of the labels of its
+// polygons. A feature whose geometry is missing, empty or neither a Polygon
+// nor a MultiPolygon gives a feature with a null geometry, so that the
+// output keeps in step with the input. Blank lines are skipped.
+//
+// Features are labelled by concurrency goroutines, each with its own
+// Labeler, and only a few features per goroutine are held in memory at once,
+// so reading waits for writing. It is an error if a line is not a feature
+// with valid coordinates, and LabelNDJSON returns on the first error,
+// leaving any read in progress to finish in the background.
+func LabelNDJSON(r io.Reader, w io.Writer, precision float64, concurrency int, opts ...Option) error {
+	return labelNDJSON(r, w, precision, concurrency, true, opts)
+}
+
+// LabelNDJSONUnordered is like LabelNDJSON but writes each label as soon as
+// it is found rather than in the order of the input, so that a slow feature
+// does not hold back those after it. Use the feature ids to match labels to
+// their features.
+func LabelNDJSONUnordered(r io.Reader, w io.Writer, precision float64, concurrency int, opts ...Option) error {
+	return labelNDJSON(r, w, precision, concurrency, false, opts)
+}
+
+// a line of NDJSON on its way through the workers
+type ndjsonItem struct {
+	line int
+	data []byte
+	out  []byte
+	err  error
+	done chan struct{} // closed once labelled, in order
+}
+
+func labelNDJSON(r io.Reader, w io.Writer, precision float64, concurrency int, ordered bool, opts []Option) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	jobs := make(chan *ndjsonItem, concurrency)
+	labelled := make(chan *ndjsonItem, concurrency) // in input order if ordered
+	stop := make(chan struct{})
+	var readErr error
+
+	go func() {
+		defer close(jobs)
+		if ordered {
+			defer close(labelled)
+		}
+		reader := bufio.NewReader(r)
+		for line := 1; ; line++ {
+			data, err := reader.ReadBytes('\n')
+			if len(data) > 0 && !isBlank(data) {
+				item := &ndjsonItem{line: line, data: data, done: make(chan struct{})}
+				select {
+				case jobs <- item:
+				case <-stop:
+					return
+				}
+				if ordered {
+					select {
+					case labelled <- item:
+					case <-stop:
+						return
+					}
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					readErr = fmt.Errorf("polylabel: reading NDJSON line %d: %v", line, err)
+				}
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := NewLabeler(opts...)
+			for item := range jobs {
+				item.out, item.err = labelFeature(l, item.data, precision)
+				if item.err != nil {
+					item.err = fmt.Errorf("polylabel: NDJSON line %d: %v", item.line, item.err)
+				}
+				close(item.done)
+				if !ordered {
+					select {
+					case labelled <- item:
+					case <-stop:
+						return
+					}
+				}
+			}
+		}()
+	}
+	if !ordered {
+		go func() {
+			wg.Wait()
+			close(labelled)
+		}()
+	}
+
+	for item := range labelled {
+		<-item.done
+		err := item.err
+		if err == nil {
+			_, err = w.Write(item.out)
+		}
+		if err != nil {
+			close(stop)
+			return err
+		}
+	}
+	return readErr
+}
+
+// report whether a line holds only whitespace
+func isBlank(data []byte) bool {
+	for _, c := range data {
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return false
+		}
+	}
+	return true
+}
+
+// label a GeoJSON feature, returning the line of the label feature
+func labelFeature(l *Labeler, data []byte, precision float64) ([]byte, error) {
+	var feature struct {
+		Type     string
+		ID       json.RawMessage
+		Geometry *struct {
+			Type        string
+			Coordinates json.RawMessage
+		}
+		Properties map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(data, &feature); err != nil {
+		return nil, err
+	}
+	if feature.Type != "Feature" {
+		return nil, errors.New("not a GeoJSON feature")
+	}
+
+	var polygons []Polygon
+	if g := feature.Geometry; g != nil {
+		var err error
+		switch g.Type {
+		case "Polygon":
+			var polygon Polygon
+			polygon, err = decodeGeoJSONPolygon(g.Coordinates)
+			polygons = []Polygon{polygon}
+		case "MultiPolygon":
+			var parts []json.RawMessage
+			if err = json.Unmarshal(g.Coordinates, &parts); err != nil {
+				break
+			}
+			for _, part := range parts {
+				var polygon Polygon
+				if polygon, err = decodeGeoJSONPolygon(part); err != nil {
+					break
+				}
+				polygons = append(polygons, polygon)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s coordinates: %v", g.Type, err)
+		}
+	}
+
+	var geometry interface{}
+	best := Result{Distance: math.Inf(-1)}
+	for _, polygon := range polygons {
+		if len(polygon) == 0 || len(polygon[0]) == 0 {
+			continue
+		}
+		if result := l.Label(polygon, precision); geometry == nil || result.Distance > best.Distance {
+			best = result
+			geometry = map[string]interface{}{"type": "Point", "coordinates": [2]float64{best.X, best.Y}}
+		}
+	}
+
+	properties := make(map[string]json.RawMessage, len(feature.Properties)+1)
+	for key, value := range feature.Properties {
+		properties[key] = value
+	}
+	properties["distance"] = json.RawMessage("null")
+	if geometry != nil && !math.IsInf(best.Distance, 0) && !math.IsNaN(best.Distance) {
+		properties["distance"] = json.RawMessage(strconv.FormatFloat(best.Distance, 'g', -1, 64))
+	}
+
+	label := map[string]interface{}{"type": "Feature", "geometry": geometry, "properties": properties}
+	if len(feature.ID) > 0 {
+		label["id"] = feature.ID
+	}
+	out, err := json.Marshal(label)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// decode the coordinates of a GeoJSON polygon
+func decodeGeoJSONPolygon(data json.RawMessage) (Polygon, error) {
+	var rings [][][]float64
+	if err := json.Unmarshal(data, &rings); err != nil {
+		return nil, err
+	}
+	polygon := make(Polygon, len(rings))
+	for i, positions := range rings {
+		polygon[i] = make(Ring, len(positions))
+		for n, position := range positions {
+			if len(position) < 2 {
+				return nil, fmt.Errorf("ring %d has a position with %d values", i, len(position))
+			}
+			polygon[i][n] = Coord{position[0], position[1]}
+		}
+	}
+	return polygon, nil
+}
@@ -0,0 +1,72 @@
+package polylabel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLabelNDJSON(t *testing.T) {
+	var input strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&input, `{"type":"Feature","id":%d,"properties":{"name":"square %d","distance":"old"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[%d,0],[%d,%d],[0,%d],[0,0]]]}}`+"\n", i, i, 2*i, 2*i, 2*i, 2*i)
+	}
+	input.WriteString("\n")
+	input.WriteString(`{"type":"Feature","id":"multi","properties":null,"geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,2],[0,2],[0,0]]],[[[10,0],[16,0],[16,6],[10,6],[10,0]]]]}}` + "\n")
+	input.WriteString(`{"type":"Feature","id":"point","geometry":{"type":"Point","coordinates":[1,2]}}`)
+
+	var ordered bytes.Buffer
+	if err := LabelNDJSON(strings.NewReader(input.String()), &ordered, 0.1, 4); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(ordered.String(), "\n"), "\n")
+	AssertEqual(t, len(lines), 52)
+	for i, line := range lines[:50] {
+		size := float64(2 * (i + 1))
+		expected := fmt.Sprintf(`{"geometry":{"coordinates":[%v,%v],"type":"Point"},"id":%d,"properties":{"distance":%v,"name":"square %d"},"type":"Feature"}`, size/2, size/2, i+1, size/2, i+1)
+		AssertEqual(t, line, expected)
+	}
+	AssertEqual(t, lines[50], `{"geometry":{"coordinates":[13,3],"type":"Point"},"id":"multi","properties":{"distance":3},"type":"Feature"}`)
+	AssertEqual(t, lines[51], `{"geometry":null,"id":"point","properties":{"distance":null},"type":"Feature"}`)
+
+	// the unordered mode writes the same lines in any order
+	var unordered bytes.Buffer
+	if err := LabelNDJSONUnordered(strings.NewReader(input.String()), &unordered, 0.1, 4); err != nil {
+		t.Fatal(err)
+	}
+	received := strings.Split(strings.TrimSuffix(unordered.String(), "\n"), "\n")
+	sort.Strings(received)
+	sort.Strings(lines)
+	AssertEqual(t, strings.Join(received, "\n"), strings.Join(lines, "\n"))
+
+	// each line of output is valid GeoJSON
+	var feature map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &feature); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLabelNDJSONErrors(t *testing.T) {
+	// the bad line follows enough good ones to keep the workers busy
+	squares := strings.Repeat(`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}`+"\n", 20)
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{"{\n", "polylabel: NDJSON line 21: unexpected end of JSON input"},
+		{`{"type":"FeatureCollection"}`, "polylabel: NDJSON line 21: not a GeoJSON feature"},
+		{`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0]]]}}`, "polylabel: NDJSON line 21: invalid Polygon coordinates: ring 0 has a position with 1 values"},
+		{`{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[3]}}`, "polylabel: NDJSON line 21: invalid MultiPolygon coordinates: json: cannot unmarshal number into Go value of type [][][]float64"},
+	} {
+		var out bytes.Buffer
+		if err := LabelNDJSON(strings.NewReader(squares+test.input), &out, 0.1, 2); err == nil || err.Error() != test.expected {
+			t.Errorf("Received %v, expected %v", err, test.expected)
+		}
+		if err := LabelNDJSONUnordered(strings.NewReader(squares+test.input), &out, 0.1, 1); err == nil || err.Error() != test.expected {
+			t.Errorf("Received %v, expected %v", err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,578 @@
+package polylabel
+
+import "math"
+
+type options struct {
+	bboxCell bool
+	parallel bool
+	tangents bool
+
+	roundOutput    bool
+	outputDecimals int
+	float32Output  bool
+	snapOutput     bool
+	snapOffset     float64
+	quantize       float64
+	quantizeRule   QuantizeRule
+	transform      func(x, y float64) (float64, float64)
+
+	containment   ContainmentRule
+	edgeWeights   [][]float64
+	separateRings bool
+	refine        bool
+	aspect        bool
+	ensureCCW     bool
+	rfc7946       bool
+	pruneSpikes   bool
+	maxVertices   int
+	validator     func(p Coord, distance float64) bool
+	minCorridor   float64
+	boundFactor   float64
+	approxBelow   float64
+	compactness   float64
+	clampInside   bool
+
+	convexCentroid    bool
+	convexMinDistance float64
+
+	stopFraction float64
+	stopWindow   int
+	maxQueue     int
+	precisionX   float64
+	precisionY   float64
+	tieBreak     bool
+	reference    Coord
+	grid         float64 // spacing of the fixed point cell centers, if any
+	viewport     bool
+	viewportBox  Rect
+	greedy       bool
+	bracket      bool
+	finest       float64
+	coarsest     float64
+
+	snapVertex       bool
+	snapVertexWithin float64
+
+	initialGuess bool
+	guess        Coord
+}
+
+// Option configures optional behaviour of Polylabel and PolylabelVerbose.
+type Option func(*options)
+
+// WithBBoxCell enables or disables evaluating the center of the bounding box
+// as an initial candidate. It is enabled by default, which gives the exact
+// center for rectangular polygons. Disabling it matches implementations that
+// only seed the search with the centroid.
+func WithBBoxCell(enabled bool) Option {
+	return func(o *options) {
+		o.bboxCell = enabled
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{bboxCell: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithParallel evaluates the four children of each subdivided cell
+// concurrently. The children are queued in a fixed order, so the result is
+// identical to the serial search. This pays off for polygons with many
+// vertices, where each distance evaluation is expensive.
+func WithParallel(enabled bool) Option {
+	return func(o *options) {
+		o.parallel = enabled
+	}
+}
+
+// WithTangentPoints fills in Result.Tangents with the nearest points of the
+// polygon outline to the label, for example to draw leader lines.
+func WithTangentPoints(enabled bool) Option {
+	return func(o *options) {
+		o.tangents = enabled
+	}
+}
+
+// WithOutputRounding rounds the coordinates of the returned label to the
+// given number of decimal places, or to integers if decimals is 0. Negative
+// values round to tens, hundreds and so on. The rounding is applied once the
+// search has finished and does not affect the reported distance.
+func WithOutputRounding(decimals int) Option {
+	return func(o *options) {
+		o.roundOutput = true
+		o.outputDecimals = decimals
+	}
+}
+
+// WithFloat32Output rounds the returned label to the nearest float32
+// coordinates, and stops splitting cells once they are smaller than the
+// spacing of float32 values at their position, since finer positions would
+// be lost to the rounding. This suits labels stored as float32, for example
+// in GPU buffers.
+func WithFloat32Output(enabled bool) Option {
+	return func(o *options) {
+		o.float32Output = enabled
+	}
+}
+
+// WithOutputTransform applies transform to the returned label, for example to
+// reproject it into another coordinate reference system. The search runs in
+// the coordinates of the polygon, where precision is measured, and only the
+// label is transformed, before any output rounding. The distance and the
+// tangent points stay in the coordinates of the polygon.
+func WithOutputTransform(transform func(x, y float64) (float64, float64)) Option {
+	return func(o *options) {
+		o.transform = transform
+	}
+}
+
+// WithSubpixelSnap moves the returned label to the nearest point whose
+// coordinates are a whole number plus offset, for example 0.5 to place labels
+// at pixel centers in raster coordinates. It is applied after any transform
+// and output rounding, and does not affect the search or the reported
+// distance.
+func WithSubpixelSnap(offset float64) Option {
+	return func(o *options) {
+		o.snapOutput = true
+		o.snapOffset = offset
+	}
+}
+
+// QuantizeRule decides which grid point WithGridQuantize moves a label to.
+type QuantizeRule int
+
+const (
+	// QuantizeRound moves a label to the nearest grid point.
+	QuantizeRound QuantizeRule = iota
+	// QuantizeFloor moves a label to the grid point below and to the left of
+	// it, the corner of the grid cell it lies in, so that labels in the same
+	// cell always share a point.
+	QuantizeFloor
+)
+
+// WithGridQuantize moves the returned label onto a grid of points cellSize
+// apart, aligned with the origin, so that nearly coincident labels of
+// neighbouring polygons can be deduplicated or clustered downstream. rule
+// chooses the grid point. It is applied after any transform and output
+// rounding but before WithSubpixelSnap, and does not affect the search or
+// the reported distance. A cellSize that is not positive disables it.
+func WithGridQuantize(cellSize float64, rule QuantizeRule) Option {
+	return func(o *options) {
+		o.quantize = cellSize
+		o.quantizeRule = rule
+	}
+}
+
+// apply the requested adjustments to the final label position
+func (o *options) output(x float64, y float64) (float64, float64) {
+	if o.transform != nil {
+		x, y = o.transform(x, y)
+	}
+	if o.roundOutput {
+		scale := math.Pow10(o.outputDecimals)
+		x = math.Round(x*scale) / scale
+		y = math.Round(y*scale) / scale
+	}
+	if o.quantize > 0 {
+		snap := math.Round
+		if o.quantizeRule == QuantizeFloor {
+			snap = math.Floor
+		}
+		x = snap(x/o.quantize) * o.quantize
+		y = snap(y/o.quantize) * o.quantize
+	}
+	if o.snapOutput {
+		x = math.Round(x-o.snapOffset) + o.snapOffset
+		y = math.Round(y-o.snapOffset) + o.snapOffset
+	}
+	if o.float32Output {
+		x, y = float64(float32(x)), float64(float32(y))
+	}
+	return x, y
+}
+
+// get the spacing of float32 values at v
+func float32ULP(v float64) float64 {
+	f := float32(math.Abs(v))
+	return float64(math.Nextafter32(f, float32(math.Inf(1))) - f)
+}
+
+// ContainmentRule decides which points are inside a polygon.
+type ContainmentRule int
+
+const (
+	// EvenOdd treats a point as inside if a ray from it crosses the rings an
+	// odd number of times. This is the default and matches other
+	// implementations, but gives surprising results for invalid polygons
+	// whose rings overlap, such as a hole overlapping another hole.
+	EvenOdd ContainmentRule = iota
+	// Winding treats a point as inside if the rings wind around it more
+	// times in the direction of the exterior ring than against it. Holes
+	// must wind in the opposite direction to the exterior, as GeoJSON
+	// requires; then regions covered by several holes, or by a hole that
+	// strays outside the exterior, are consistently outside.
+	Winding
+)
+
+// WithContainmentRule sets the rule used to decide which points are inside
+// the polygon.
+func WithContainmentRule(rule ContainmentRule) Option {
+	return func(o *options) {
+		o.containment = rule
+	}
+}
+
+// WithRingsAsSeparate treats every ring of the polygon as a separate solid
+// shape rather than treating the rings after the first as holes, and labels
+// the largest of them. This suits data that packs unrelated shapes into the
+// rings of one polygon. The containment rule is ignored, and rings are not
+// expected to overlap.
+func WithRingsAsSeparate(enabled bool) Option {
+	return func(o *options) {
+		o.separateRings = enabled
+	}
+}
+
+// WithPreferCentroidWhenConvex skips the search for convex polygons without
+// holes whose centroid is further than minDistance from the outline, and
+// returns the centroid. The centroid of a convex polygon is always inside it
+// and is often an acceptable label, at a fraction of the cost of the search,
+// though it is not the pole of inaccessibility. Other polygons are searched
+// as usual.
+func WithPreferCentroidWhenConvex(minDistance float64) Option {
+	return func(o *options) {
+		o.convexCentroid = true
+		o.convexMinDistance = minDistance
+	}
+}
+
+// WithMaxQueueSize bounds the memory used by a search, for example for
+// untrusted input, by holding at most n cells in the queue between steps.
+// When the queue grows past n the least promising cells are discarded, so
+// the search may miss the best label and the result is then no longer
+// guaranteed to be within precision of it. Stats.Discarded reports whether
+// this happened. A size that is not positive leaves the queue unbounded.
+func WithMaxQueueSize(n int) Option {
+	return func(o *options) {
+		o.maxQueue = n
+	}
+}
+
+// WithGreedySearch processes cells in order of the distance at their centers
+// rather than the greatest distance they could contain, so the search dives
+// into the most promising region first and good labels turn up sooner. This
+// suits an anytime search, run with Search.Run for a limited number of steps
+// or stopped by WithRelativeStop, that is happy with a good enough label. A
+// search stopped early loses the guarantee that its label is within
+// precision of the best, which processing by bound gives at every step. A
+// search run to completion still meets it, though it may process more cells.
+func WithGreedySearch(enabled bool) Option {
+	return func(o *options) {
+		o.greedy = enabled
+	}
+}
+
+// WithResultValidator only accepts labels for which accept returns true, for
+// example to keep a label within a district. Candidates are passed in the
+// coordinates of the polygon with their distance from the outline, and the
+// search returns the best accepted candidate, to within precision, even where
+// that is far from the best overall. If accept rejects every candidate,
+// Result.Rejected is set and the best rejected candidate is returned. The
+// search explores more cells while no candidate has been accepted, and
+// around rejected candidates better than the best accepted, down to cells as
+// small as precision, so precision must be positive.
+func WithResultValidator(accept func(p Coord, distance float64) bool) Option {
+	return func(o *options) {
+		o.validator = accept
+	}
+}
+
+// WithMinCorridorWidth only accepts labels at least width/2 from the
+// outline, so that a label width across always fits around them. Cells that
+// cannot contain such a point are discarded, which keeps labels out of thin
+// necks and corridors even where the best distance found so far is there. It
+// combines with WithResultValidator, and as for that option Result.Rejected
+// is set if the polygon is nowhere width across, and precision must be
+// positive.
+func WithMinCorridorWidth(width float64) Option {
+	return func(o *options) {
+		o.minCorridor = width
+	}
+}
+
+// report whether a candidate label is accepted by the result validator and
+// the minimum corridor width
+func (o *options) accepts(c cell) bool {
+	if o.minCorridor > 0 && !(c.d >= o.minCorridor/2) {
+		return false
+	}
+	return o.validator == nil || o.validator(Coord{c.x, c.y}, c.d)
+}
+
+// WithApproximateBelow saves distance evaluations deep in the search by
+// estimating the distance at the center of any cell smaller than size across
+// from its parent rather than computing it. Such a cell is centered h√2 from
+// the center of its parent, where h is its half size, so its distance is at
+// least that of the parent less h√2 and the estimate is taken as that lower
+// bound. An estimate can never improve on the best label, which is at least
+// as good as the parent, and the bound of its cell is no more than the
+// parent's distance, so such cells are pruned at once and are not made at
+// all. The label is then within the larger of precision and √2 times size
+// of the best achievable. A size that is not positive disables the
+// approximation.
+func WithApproximateBelow(size float64) Option {
+	return func(o *options) {
+		o.approxBelow = size
+	}
+}
+
+// WithRelativeStop also ends the search once the best distance has not grown
+// by more than fraction of itself over the last window cells processed, which
+// adapts to the scale of the polygon. The result is then no longer
+// guaranteed to be within precision of the best achievable; pass a precision
+// of 0 to rely on this rule alone. A window that is not positive disables it.
+func WithRelativeStop(fraction float64, window int) Option {
+	return func(o *options) {
+		o.stopFraction = fraction
+		o.stopWindow = window
+	}
+}
+
+// WithPrecisionXY sets separate precisions along X and Y in place of the
+// precision argument, for coordinates whose axes have different units, such
+// as time against value. A step of px along X then counts the same as a step
+// of py along Y: X is stretched by py/px during the search, which runs at
+// precision py, so Result.Distance, Score and Corners are in the units of Y.
+// Cells are squares in the stretched coordinates and px by py rectangles in
+// the original ones, so the bound on the distance within a cell, its half
+// diagonal, holds in the units of Y whatever the ratio. Both precisions must
+// be positive for the option to apply. PolylabelSource and the functions
+// whose options are limited as for it ignore the option.
+func WithPrecisionXY(px float64, py float64) Option {
+	return func(o *options) {
+		o.precisionX = px
+		o.precisionY = py
+	}
+}
+
+// WithPrecisionBracket adapts the precision to each polygon of a batch of
+// polygons of very different sizes, keeping it between finest and coarsest.
+// The precision argument is the target. It is lowered to coarsest if it is
+// coarser, and to a sixteenth of the shorter side of the bounding box, so
+// that a polygon that is small beside the target is still searched rather
+// than labelled at its centroid. It is never lowered below finest, however,
+// which caps the work spent on tiny polygons. That is, the precision used is
+// max(finest, min(precision, coarsest, side/16)).
+func WithPrecisionBracket(finest float64, coarsest float64) Option {
+	return func(o *options) {
+		o.bracket = true
+		o.finest = finest
+		o.coarsest = coarsest
+	}
+}
+
+// WithInitialGuess starts the search from guess, such as the label of an
+// earlier version of the polygon, if it is further from the outline than the
+// centroid. A good guess lets more cells be pruned straight away, so fewer are
+// subdivided. A poor one, even outside the polygon, is harmless, as the
+// centroid is kept instead and the search finds the same label. It is
+// ignored by PolylabelInteger.
+func WithInitialGuess(guess Coord) Option {
+	return func(o *options) {
+		o.initialGuess = true
+		o.guess = guess
+	}
+}
+
+// the precision of the search for a polygon whose bounding box has cellSize
+// as its shorter side, as set by WithPrecisionBracket
+func (o *options) bracketPrecision(precision float64, cellSize float64) float64 {
+	if !o.bracket {
+		return precision
+	}
+	return math.Max(o.finest, math.Min(precision, math.Min(o.coarsest, cellSize/16)))
+}
+
+// the factor by which WithPrecisionXY stretches X
+func (o *options) xScale() float64 {
+	if o.precisionX > 0 && o.precisionY > 0 {
+		return o.precisionY / o.precisionX
+	}
+	return 1
+}
+
+// apply WithPrecisionXY, returning the polygon and precision to search and
+// options whose validator sees the original coordinates
+func (o *options) stretchX(polygon Polygon, precision float64) (Polygon, float64, *options) {
+	if !(o.precisionX > 0 && o.precisionY > 0) {
+		return polygon, precision, o
+	}
+	scale := o.xScale()
+	if scale == 1 {
+		return polygon, o.precisionY, o
+	}
+	stretched := make(Polygon, len(polygon))
+	for i, ring := range polygon {
+		stretched[i] = make(Ring, len(ring))
+		for n, coord := range ring {
+			stretched[i][n] = Coord{coord[0] * scale, coord[1]}
+		}
+	}
+	if o.validator != nil {
+		accept := o.validator
+		copied := *o
+		copied.validator = func(p Coord, distance float64) bool {
+			return accept(Coord{p[0] / scale, p[1]}, distance)
+		}
+		o = &copied
+	}
+	return stretched, o.precisionY, o
+}
+
+// WithTieBreakToward prefers labels close to reference, such as the label of
+// the previous frame of an animation, to keep labels from jumping between
+// places with nearly the same room. Of the candidates within precision of
+// the best distance found, the one closest to reference is returned, so the
+// distance is within twice precision of the best achievable rather than
+// within precision. The candidates are kept as the search goes, which costs
+// memory for polygons with a broad plateau of near best cells.
+func WithTieBreakToward(reference Coord) Option {
+	return func(o *options) {
+		o.tieBreak = true
+		o.reference = reference
+	}
+}
+
+// the point that tied cells are judged by their distance to: the reference
+// set by WithTieBreakToward, stretched as the polygon is, or else the center
+// of the bounding box
+func (o *options) tieCenter(minX float64, minY float64, maxX float64, maxY float64) Coord {
+	if o.tieBreak {
+		return Coord{o.reference[0] * o.xScale(), o.reference[1]}
+	}
+	return Coord{(minX + maxX) / 2, (minY + maxY) / 2}
+}
+
+// WithViewport keeps the label away from the edges of the viewport from
+// minX, minY to maxX, maxY as well as from the polygon outline, so that it
+// sits comfortably on screen instead of clinging to the border of the map as
+// the view is panned. The edges of the viewport count as part of the outline,
+// so Result.Distance is the distance to the nearer of the two, and the part
+// of the polygon outside the viewport counts as outside.
+func WithViewport(minX float64, minY float64, maxX float64, maxY float64) Option {
+	return func(o *options) {
+		o.viewport = true
+		o.viewportBox = Rect{minX, minY, maxX, maxY}
+	}
+}
+
+// signed distance from point to the edges of the viewport set by
+// WithViewport (negative if point is outside), stretched as the polygon is
+func (o *options) viewportDistance(x float64, y float64) float64 {
+	scale := o.xScale()
+	return rectDistance(o.viewportBox.MinX*scale, o.viewportBox.MinY, o.viewportBox.MaxX*scale, o.viewportBox.MaxY, x, y)
+}
+
+// signed distance from a point to the outline of a rectangle (negative if
+// the point is outside)
+func rectDistance(minX float64, minY float64, maxX float64, maxY float64, x float64, y float64) float64 {
+	dx := math.Max(minX-x, x-maxX)
+	dy := math.Max(minY-y, y-maxY)
+	if dx <= 0 && dy <= 0 {
+		return -math.Max(dx, dy)
+	}
+	return -math.Hypot(math.Max(dx, 0), math.Max(dy, 0))
+}
+
+// the bounding box of the area to search
+func (o *options) boundingBox(polygon Polygon) (minX float64, minY float64, maxX float64, maxY float64) {
+	if o.separateRings {
+		return ringsBoundingBox(polygon)
+	}
+	return boundingBox(polygon)
+}
+
+// the signed distance function for a polygon
+func (o *options) distance(polygon Polygon) func(x float64, y float64) float64 {
+	distance := func(x, y float64) float64 {
+		return pointToPolygonDistance(x, y, polygon)
+	}
+	if o.separateRings {
+		distance = func(x, y float64) float64 {
+			return pointToRingsDistance(x, y, polygon)
+		}
+	} else if o.containment == Winding {
+		distance = func(x, y float64) float64 {
+			return pointToPolygonDistanceWinding(x, y, polygon)
+		}
+	}
+	if o.edgeWeights != nil {
+		// take the sign from the containment rule and the size from the
+		// weighted edges
+		signed := distance
+		distance = func(x, y float64) float64 {
+			d := weightedOutlineDistance(x, y, polygon, o.edgeWeights)
+			if math.Signbit(signed(x, y)) {
+				return -d
+			}
+			return d
+		}
+	}
+	if o.viewport {
+		outline := distance
+		distance = func(x, y float64) float64 {
+			return math.Min(outline(x, y), o.viewportDistance(x, y))
+		}
+	}
+	return distance
+}
+
+// evaluates cells of a polygon with its signed distance function
+func (o *options) cellFunc(polygon Polygon) cellFunc {
+	distance := o.distance(polygon)
+	bound := o.bound()
+	if o.edgeWeights != nil {
+		// a weighted distance can change faster than the distance itself,
+		// by up to the largest weight, and no slower than the distance to
+		// the viewport
+		weight := maxEdgeWeight(o.edgeWeights)
+		if o.viewport {
+			weight = math.Max(weight, 1)
+		}
+		bound *= weight
+	}
+	cellAt := func(x, y, h float64) cell {
+		d := distance(x, y)
+		return cell{x, y, h, d, d + h*bound}
+	}
+	if o.compactness > 0 {
+		return compactCells(cellAt, distance, o.compactness)
+	}
+	return cellAt
+}
+
+// WithBoundFactor sets the factor of the half size of a cell by which the
+// distance at its center is taken to bound the distance anywhere in the
+// cell. The default is the square root of two, the half diagonal, which is
+// as tight as holds for any polygon, and matches mapbox/polylabel. A port
+// of another implementation with a different bound can set its factor to
+// reproduce its labels exactly. A smaller factor prunes more cells and so
+// runs faster but may miss the best label by more than precision, and a
+// larger one explores cells that cannot help. A factor that is not positive
+// restores the default.
+func WithBoundFactor(factor float64) Option {
+	return func(o *options) {
+		o.boundFactor = factor
+	}
+}
+
+// get the factor set by WithBoundFactor
+func (o *options) bound() float64 {
+	if o.boundFactor > 0 {
+		return o.boundFactor
+	}
+	return math.Sqrt2
+}
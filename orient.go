@@ -0,0 +1,201 @@
+package polylabel
+
+import "math"
+
+// EnsureCCW returns polygon with its exterior ring counterclockwise and its
+// holes clockwise, as GeoJSON requires, reversing rings as needed. polygon is
+// not modified, and rings that are already correctly oriented are shared with
+// it.
+func EnsureCCW(polygon Polygon) Polygon {
+	oriented, _ := orientRings(polygon)
+	return oriented
+}
+
+// WithEnsureCCW orients the polygon with EnsureCCW before labelling it, so
+// that the Winding containment rule and the centroid do not depend on the
+// winding order of the input. Edge weights stay with their edges when a ring
+// is reversed.
+func WithEnsureCCW(enabled bool) Option {
+	return func(o *options) {
+		o.ensureCCW = enabled
+	}
+}
+
+// orient the rings of polygon as EnsureCCW does, reporting which rings were
+// reversed, or nil if none were
+func orientRings(polygon Polygon) (Polygon, []bool) {
+	oriented := polygon
+	var reversed []bool
+	for i, ring := range polygon {
+		area := ringArea(ring)
+		if (i == 0 && area >= 0) || (i > 0 && area <= 0) {
+			continue
+		}
+		if reversed == nil {
+			oriented = append(Polygon(nil), polygon...)
+			reversed = make([]bool, len(polygon))
+		}
+		oriented[i] = reverseRing(ring)
+		reversed[i] = true
+	}
+	return oriented, reversed
+}
+
+// get a reversed copy of a ring
+func reverseRing(ring Ring) Ring {
+	reversed := make(Ring, len(ring))
+	for i, c := range ring {
+		reversed[len(ring)-1-i] = c
+	}
+	return reversed
+}
+
+// NormalizeRFC7946 returns polygon in the form RFC 7946 requires of GeoJSON
+// polygons: every ring closed, with its last position repeating the first,
+// the exterior ring counterclockwise and the holes clockwise. Unclosed rings
+// are closed and wrongly wound rings are reversed. polygon is not modified,
+// and rings that are already closed and correctly oriented are shared with
+// it.
+func NormalizeRFC7946(polygon Polygon) Polygon {
+	return EnsureCCW(closeRings(polygon))
+}
+
+// WithRFC7946 normalizes the polygon with NormalizeRFC7946 before labelling
+// it. Edge weights stay with their edges when a ring is reversed.
+func WithRFC7946(enabled bool) Option {
+	return func(o *options) {
+		o.rfc7946 = enabled
+	}
+}
+
+// close any rings whose last coordinate does not repeat the first, sharing
+// the rings that are already closed
+func closeRings(polygon Polygon) Polygon {
+	closed := polygon
+	copied := false
+	for i, ring := range polygon {
+		if len(ring) == 0 || ring[0] == ring[len(ring)-1] {
+			continue
+		}
+		if !copied {
+			closed = append(Polygon(nil), polygon...)
+			copied = true
+		}
+		closed[i] = append(append(make(Ring, 0, len(ring)+1), ring...), ring[0])
+	}
+	return closed
+}
+
+// orient the polygon if requested, returning options whose edge weights are
+// reversed along with their rings
+func (o *options) orientPolygon(polygon Polygon) (Polygon, *options) {
+	if o.rfc7946 {
+		polygon = closeRings(polygon)
+	} else if !o.ensureCCW {
+		return polygon, o
+	}
+	oriented, reversed := orientRings(polygon)
+	if reversed == nil || o.edgeWeights == nil {
+		return oriented, o
+	}
+	copied := *o
+	copied.edgeWeights = append([][]float64(nil), o.edgeWeights...)
+	for i, r := range reversed {
+		if !r || i >= len(o.edgeWeights) {
+			continue
+		}
+		edges := len(polygon[i]) - 1
+		weights := make([]float64, edges)
+		for n := range weights {
+			weights[n] = edgeWeight(o.edgeWeights, i, edges-1-n)
+		}
+		copied.edgeWeights[i] = weights
+	}
+	return oriented, &copied
+}
+
+// report whether a ring is convex: it turns the same way at every vertex and
+// winds around only once, which rules out stars. Collinear and repeated
+// vertices are allowed.
+func isConvex(ring Ring) bool {
+	n := len(ring) - 1 // the last vertex repeats the first
+	if n < 3 {
+		return false
+	}
+	sign := 0.0
+	turning := 0.0
+	for i := 0; i < n; i++ {
+		a, b, c := ring[i], ring[(i+1)%n], ring[(i+2)%n]
+		ux, uy := b[0]-a[0], b[1]-a[1]
+		vx, vy := c[0]-b[0], c[1]-b[1]
+		cross := ux*vy - uy*vx
+		if cross != 0 {
+			if sign == 0 {
+				sign = math.Copysign(1, cross)
+			} else if sign*cross < 0 {
+				return false
+			}
+		}
+		turning += math.Atan2(cross, ux*vx+uy*vy)
+	}
+	return math.Abs(math.Abs(turning)-2*math.Pi) < 1e-6
+}
+
+// SplitByWinding splits a polygon whose rings pack several polygons together,
+// telling exterior rings from holes only by their winding order, into
+// separate polygons with holes. Rings wound the same way as the first ring
+// are exteriors and the rest are holes, so either winding convention is
+// accepted. Each hole goes to the smallest exterior that contains it, and a
+// hole outside every exterior is taken to be an exterior wound the wrong way.
+// Rings with no area are dropped. The polygons are returned in the order of
+// their exterior rings, and can be labelled separately or the largest picked
+// out.
+func SplitByWinding(polygon Polygon) []Polygon {
+	polygon = closeRings(polygon)
+	var exteriorSign float64
+	var polygons []Polygon
+	var areas []float64
+	var holes []Ring
+	for _, ring := range polygon {
+		area := ringArea(ring)
+		if area == 0 {
+			continue
+		}
+		if exteriorSign == 0 {
+			exteriorSign = math.Copysign(1, area)
+		}
+		if area*exteriorSign > 0 {
+			polygons = append(polygons, Polygon{ring})
+			areas = append(areas, math.Abs(area))
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	for _, hole := range holes {
+		smallest := -1
+		for i, p := range polygons {
+			if (smallest < 0 || areas[i] < areas[smallest]) && ringInside(hole, p[0]) {
+				smallest = i
+			}
+		}
+		if smallest < 0 {
+			polygons = append(polygons, Polygon{hole})
+			areas = append(areas, math.Abs(ringArea(hole)))
+			continue
+		}
+		polygons[smallest] = append(polygons[smallest], hole)
+	}
+	return polygons
+}
+
+// report whether inner lies inside outer, judged by the first vertex of inner
+// that is not on the outline of outer
+func ringInside(inner Ring, outer Ring) bool {
+	for _, c := range inner {
+		if d := pointToPolygonDistance(c[0], c[1], Polygon{outer}); d != 0 {
+			return d > 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEnsureCCW(t *testing.T) {
+	exterior := Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}
+	hole := Ring{Coord{2, 2}, Coord{2, 4}, Coord{4, 4}, Coord{4, 2}, Coord{2, 2}}
+	oriented := Polygon{exterior, hole}
+	AssertEqual(t, &EnsureCCW(oriented)[0][0], &oriented[0][0])
+
+	reversed := Polygon{reverseRing(exterior), reverseRing(hole)}
+	if received := EnsureCCW(reversed); !reflect.DeepEqual(received, oriented) {
+		t.Errorf("Received %v, expected %v", received, oriented)
+	}
+	// the input is left alone
+	AssertEqual(t, reversed[0][1], Coord{0, 10})
+}
+
+func TestWithEnsureCCW(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(EnsureCCW(polygon), 1.0, WithContainmentRule(Winding))
+
+	// every combination of ring orientations gives the same label
+	variants := []Polygon{
+		polygon,
+		{reverseRing(polygon[0])},
+	}
+	variants[1] = append(variants[1], polygon[1:]...)
+	for _, variant := range variants {
+		for _, rule := range []ContainmentRule{EvenOdd, Winding} {
+			result := PolylabelVerbose(variant, 1.0, WithEnsureCCW(true), WithContainmentRule(rule))
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("Received %v, expected %v", result, expected)
+			}
+		}
+	}
+
+	// edge weights follow their edges when a ring is reversed
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	clockwise := Polygon{reverseRing(square[0])}
+	weights := [][]float64{{0.5}}
+	reversedWeights := [][]float64{{1, 1, 1, 0.5}}
+	expected = PolylabelVerbose(square, 0.01, WithEdgeWeights(weights))
+	result := PolylabelVerbose(clockwise, 0.01, WithEdgeWeights(reversedWeights), WithEnsureCCW(true))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestNormalizeRFC7946(t *testing.T) {
+	exterior := Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}
+	hole := Ring{Coord{2, 2}, Coord{2, 4}, Coord{4, 4}, Coord{4, 2}, Coord{2, 2}}
+	expected := Polygon{exterior, hole}
+	label := PolylabelVerbose(expected, 0.1, WithContainmentRule(Winding))
+
+	// every combination of clockwise and unclosed rings
+	variants := func(ring Ring) []Ring {
+		reversed := reverseRing(ring)
+		return []Ring{ring, reversed, ring[:len(ring)-1], reversed[:len(reversed)-1]}
+	}
+	for _, e := range variants(exterior) {
+		for _, h := range variants(hole) {
+			polygon := Polygon{e, h}
+			normalized := NormalizeRFC7946(polygon)
+			for i, ring := range normalized {
+				AssertEqual(t, len(ring), 5)
+				AssertEqual(t, ring[0], ring[4])
+				if area := ringArea(ring); (i == 0) != (area > 0) {
+					t.Errorf("Received ring %d with area %v in %v", i, area, polygon)
+				}
+			}
+			// the input is left alone
+			AssertEqual(t, len(polygon[0]), len(e))
+
+			result := PolylabelVerbose(polygon, 0.1, WithRFC7946(true), WithContainmentRule(Winding))
+			if !reflect.DeepEqual(result, label) {
+				t.Errorf("Received %v for %v, expected %v", result, polygon, label)
+			}
+		}
+	}
+}
+
+func TestIsConvex(t *testing.T) {
+	square := Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}
+	AssertEqual(t, isConvex(square), true)
+	AssertEqual(t, isConvex(reverseRing(square)), true)
+	AssertEqual(t, isConvex(Ring{Coord{0, 0}, Coord{2, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}), true)
+	AssertEqual(t, isConvex(Ring{Coord{0, 0}, Coord{4, 0}, Coord{1, 1}, Coord{0, 4}, Coord{0, 0}}), false)
+	AssertEqual(t, isConvex(Ring{Coord{0, 0}, Coord{4, 0}, Coord{0, 0}}), false)
+
+	// a pentagram turns the same way at every point but winds twice
+	star := Ring{}
+	for i := 0; i <= 5; i++ {
+		angle := 4 * math.Pi * float64(i%5) / 5
+		star = append(star, Coord{math.Cos(angle), math.Sin(angle)})
+	}
+	AssertEqual(t, isConvex(star), false)
+}
+
+func TestWithPreferCentroidWhenConvex(t *testing.T) {
+	triangle := Polygon{Ring{Coord{0, 0}, Coord{9, 0}, Coord{0, 3}, Coord{0, 0}}}
+	result, stats := PolylabelStats(triangle, 0.01, WithPreferCentroidWhenConvex(0.5))
+	AssertEqual(t, result.X, 3.0)
+	AssertEqual(t, result.Y, 1.0)
+	AssertEqual(t, stats.Subdivisions, 0)
+
+	// a centroid too close to the outline, or a polygon with a hole or
+	// concave corner, is searched as usual
+	for _, polygon := range []Polygon{
+		triangle,
+		{triangle[0], Ring{Coord{1, 0.5}, Coord{1, 1}, Coord{2, 0.5}, Coord{1, 0.5}}},
+		{Ring{Coord{0, 0}, Coord{9, 0}, Coord{1, 1}, Coord{0, 3}, Coord{0, 0}}},
+	} {
+		minDistance := 0.5
+		if len(polygon) == 1 && len(polygon[0]) == 4 {
+			minDistance = 5
+		}
+		expected := PolylabelVerbose(polygon, 0.01)
+		result := PolylabelVerbose(polygon, 0.01, WithPreferCentroidWhenConvex(minDistance))
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v, expected %v", result, expected)
+		}
+	}
+}
+
+func TestSplitByWinding(t *testing.T) {
+	// a counterclockwise square from x0, y0 to x1, y1
+	square := func(x0, y0, x1, y1 float64) Ring {
+		return Ring{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}
+	}
+	left := square(0, 0, 10, 10)
+	right := square(20, 0, 30, 10)
+	lake := reverseRing(square(22, 2, 28, 8))
+	island := square(24, 4, 26, 6)
+	pond := reverseRing(square(1, 1, 4, 4))
+	stray := reverseRing(square(50, 0, 52, 2))
+	line := Ring{{0, 0}, {5, 5}, {0, 0}}
+
+	packed := Polygon{left, right, lake, island, pond, line, stray}
+	expected := []Polygon{
+		{left, pond},
+		{right, lake},
+		{island},
+		{stray},
+	}
+	if received := SplitByWinding(packed); !reflect.DeepEqual(received, expected) {
+		t.Errorf("Received %v, expected %v", received, expected)
+	}
+
+	// the opposite convention, with clockwise exteriors, splits the same way
+	reversed := make(Polygon, len(packed))
+	for i, ring := range packed {
+		reversed[i] = reverseRing(ring)
+	}
+	received := SplitByWinding(reversed)
+	AssertEqual(t, len(received), len(expected))
+	for i, polygon := range received {
+		AssertEqual(t, len(polygon), len(expected[i]))
+		for j, ring := range polygon {
+			if !reflect.DeepEqual(ring, reverseRing(expected[i][j])) {
+				t.Errorf("Received %v for ring %d of polygon %d, expected %v", ring, j, i, reverseRing(expected[i][j]))
+			}
+		}
+	}
+
+	AssertEqual(t, len(SplitByWinding(Polygon{})), 0)
+}
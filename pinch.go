@@ -0,0 +1,125 @@
+package polylabel
+
+import "math"
+
+// a vertex of a ring that comes close to another part of the same ring
+type pinch struct {
+	vertex  int   // index of the vertex
+	segment int   // index of the first vertex of the nearby segment
+	point   Coord // nearest point of the segment to the vertex
+}
+
+// parts of a ring closer to each other than this many times the tolerance,
+// measured along the ring, are neighbours rather than a pinch
+const pinchMinPathFactor = 8
+
+// find the vertices of a closed ring within tolerance of a segment of the
+// same ring that is far from them along the ring
+func findPinches(ring Ring, tolerance float64) []pinch {
+	n := len(ring) - 1
+	if n < 3 {
+		return nil
+	}
+
+	// cumulative length along the ring up to each vertex
+	lengths := make([]float64, n+1)
+	for i := 0; i < n; i++ {
+		lengths[i+1] = lengths[i] + math.Hypot(ring[i+1][0]-ring[i][0], ring[i+1][1]-ring[i][1])
+	}
+	perimeter := lengths[n]
+
+	var pinches []pinch
+	for i := 0; i < n; i++ {
+		v := ring[i]
+		for j := 0; j < n; j++ {
+			a, b := ring[j], ring[j+1]
+			px, py := segmentClosestPoint(v[0], v[1], a, b)
+			if math.Hypot(px-v[0], py-v[1]) > tolerance {
+				continue
+			}
+			along := math.Abs(lengths[j] + math.Hypot(px-a[0], py-a[1]) - lengths[i])
+			if math.Min(along, perimeter-along) <= pinchMinPathFactor*tolerance {
+				continue
+			}
+			pinches = append(pinches, pinch{i, j, Coord{px, py}})
+			break
+		}
+	}
+	return pinches
+}
+
+// PinchPoints returns the vertices at which a ring of polygon comes within
+// tolerance of another part of itself, such as the waist of an hourglass.
+// Parts of a ring less than eight times tolerance apart along the ring are
+// not counted, so that a vertex is not reported for being close to its own
+// edges.
+func PinchPoints(polygon Polygon, tolerance float64) []Coord {
+	var points []Coord
+	for _, ring := range closeRings(polygon) {
+		for _, p := range findPinches(ring, tolerance) {
+			points = append(points, ring[p.vertex])
+		}
+	}
+	return points
+}
+
+// split the exterior ring of a polygon at its pinch points into separate
+// polygons, assigning each hole to the part that contains it
+func splitLobes(polygon Polygon, tolerance float64) []Polygon {
+	polygon = closeRings(polygon)
+	pinches := findPinches(polygon[0], tolerance)
+	if len(pinches) == 0 {
+		return []Polygon{polygon}
+	}
+
+	exterior := polygon[0]
+	n := len(exterior) - 1
+	p := pinches[0]
+
+	// walk from the vertex to the pinch point and back
+	first := Ring{exterior[p.vertex]}
+	for k := p.vertex; k != p.segment; {
+		k = (k + 1) % n
+		first = append(first, exterior[k])
+	}
+	first = append(first, p.point, exterior[p.vertex])
+
+	// walk from the pinch point round the rest of the ring to the vertex
+	second := Ring{p.point}
+	for k := p.segment; k != p.vertex; {
+		k = (k + 1) % n
+		second = append(second, exterior[k])
+	}
+	second = append(second, p.point)
+
+	lobes := []Polygon{{first}, {second}}
+	for _, hole := range polygon[1:] {
+		for i, lobe := range lobes {
+			if pointToPolygonDistance(hole[0][0], hole[0][1], Polygon{lobe[0]}) > 0 {
+				lobes[i] = append(lobe, hole)
+				break
+			}
+		}
+	}
+
+	// each part may be pinched again
+	var polygons []Polygon
+	for _, lobe := range lobes {
+		polygons = append(polygons, splitLobes(lobe, tolerance)...)
+	}
+	return polygons
+}
+
+// PolylabelLobes splits polygon at the points where its exterior ring comes
+// within tolerance of itself, as found by PinchPoints, and labels each of the
+// resulting lobes separately. Pinches between the exterior and a hole do not
+// split the polygon. The results are in the order the lobes appear along the
+// exterior ring.
+func PolylabelLobes(polygon Polygon, precision float64, tolerance float64, opts ...Option) []Result {
+	lobes := splitLobes(polygon, tolerance)
+	results := make([]Result, len(lobes))
+	for i, lobe := range lobes {
+		results[i] = PolylabelVerbose(lobe, precision, opts...)
+	}
+	return results
+}
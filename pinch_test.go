@@ -0,0 +1,74 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// two squares joined by a waist 0.1 wide
+var hourglass = Polygon{Ring{
+	Coord{0, 0}, Coord{4, 0}, Coord{5, 1.95}, Coord{6, 0}, Coord{10, 0}, Coord{10, 4},
+	Coord{6, 4}, Coord{5, 2.05}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0},
+}}
+
+func TestPinchPoints(t *testing.T) {
+	points := PinchPoints(hourglass, 0.2)
+	expected := []Coord{{5, 1.95}, {5, 2.05}}
+	if !reflect.DeepEqual(points, expected) {
+		t.Errorf("Received %v, expected %v", points, expected)
+	}
+
+	if points := PinchPoints(hourglass, 0.05); len(points) != 0 {
+		t.Errorf("Received %v, expected no pinch points within a smaller tolerance", points)
+	}
+
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	if points := PinchPoints(square, 0.2); len(points) != 0 {
+		t.Errorf("Received %v, expected no pinch points for a square", points)
+	}
+}
+
+func TestPolylabelLobes(t *testing.T) {
+	// a hole in the right hand lobe
+	polygon := append(Polygon{}, hourglass[0], Ring{Coord{7, 1}, Coord{9, 1}, Coord{9, 3}, Coord{7, 3}, Coord{7, 1}})
+
+	results := PolylabelLobes(polygon, 0.01, 0.2)
+	if len(results) != 2 {
+		t.Fatalf("Received %v lobes, expected 2", len(results))
+	}
+	left, right := results[0], results[1]
+	if left.X > right.X {
+		left, right = right, left
+	}
+	if left.X >= 5 || math.Abs(left.Distance-2) > 0.01 {
+		t.Errorf("Received %v for the left lobe", left)
+	}
+	// the hole leaves less room in the right lobe
+	if right.X <= 5 || right.Distance >= 1 {
+		t.Errorf("Received %v for the right lobe with a hole", right)
+	}
+
+	results = PolylabelLobes(polygon, 0.01, 0.05)
+	if len(results) != 1 {
+		t.Errorf("Received %v lobes, expected 1", len(results))
+	}
+}
+
+func TestPinchOpenRing(t *testing.T) {
+	// the hourglass as an open ring ending at a pinch point, whose edge back
+	// to the first vertex is closed as it is by Polylabel
+	open := Polygon{Ring{
+		Coord{4, 4}, Coord{0, 4}, Coord{0, 0}, Coord{4, 0}, Coord{5, 1.95},
+		Coord{6, 0}, Coord{10, 0}, Coord{10, 4}, Coord{6, 4}, Coord{5, 2.05},
+	}}
+	closed := Polygon{append(append(Ring(nil), open[0]...), open[0][0])}
+	points := PinchPoints(open, 0.2)
+	if expected := []Coord{{5, 1.95}, {5, 2.05}}; !reflect.DeepEqual(points, expected) {
+		t.Errorf("Received %v, expected %v", points, expected)
+	}
+	results := PolylabelLobes(open, 0.01, 0.2)
+	if expected := PolylabelLobes(closed, 0.01, 0.2); !reflect.DeepEqual(results, expected) {
+		t.Errorf("Received %v, expected %v", results, expected)
+	}
+}
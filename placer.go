@@ -0,0 +1,107 @@
+package polylabel
+
+import "math"
+
+// LabelPlacer places labels one at a time, keeping each clear of the labels
+// it has already placed, as a map renderer does when labelling many
+// features. The boxes of placed labels are held in a grid of square cells
+// for quick collision tests. A LabelPlacer is not safe for concurrent use.
+type LabelPlacer struct {
+	o        *options
+	cellSize float64
+	grid     map[[2]int][]int // indices of the placed boxes in each cell
+	placed   []Rect
+}
+
+// NewLabelPlacer returns a LabelPlacer that applies opts to every polygon it
+// labels. cellSize is the side of the cells of the collision grid, and is
+// best about the size of a typical label; if it is not positive, every box
+// is tested against every other.
+func NewLabelPlacer(cellSize float64, opts ...Option) *LabelPlacer {
+	return &LabelPlacer{o: newOptions(opts), cellSize: cellSize, grid: make(map[[2]int][]int)}
+}
+
+// TryPlace labels polygon with a box of labelSize, its width and height,
+// centered on the label. If the box around the pole of inaccessibility
+// overlaps a placed label, the search goes on to the best point inside the
+// polygon, to within precision, whose box is clear, as for
+// WithResultValidator. Boxes may touch without overlapping. If there is such
+// a point its box is placed and it is returned with true. Otherwise nothing
+// is placed and the best label is returned with false. labelSize and the
+// boxes are in the coordinates of the polygon, and the options that transform
+// or round the output apply only to the label returned. precision must be
+// positive.
+func (p *LabelPlacer) TryPlace(polygon Polygon, precision float64, labelSize Coord) (Coord, bool) {
+	halfW, halfH := labelSize[0]/2, labelSize[1]/2
+	box := func(c Coord) Rect {
+		return Rect{c[0] - halfW, c[1] - halfH, c[0] + halfW, c[1] + halfH}
+	}
+
+	accept := p.o.validator
+	copied := *p.o
+	copied.validator = func(c Coord, distance float64) bool {
+		if distance <= 0 || p.collides(box(c)) {
+			return false
+		}
+		return accept == nil || accept(c, distance)
+	}
+	s := startSearch(polygon, precision, &copied, nil)
+	s.Run(0)
+
+	// boxes are placed in the coordinates of the polygon, and only the label
+	// returned is transformed or rounded
+	result := s.searchResult(s.precision)
+	label := Coord{result.X, result.Y}
+	ok := !result.Rejected && s.cells != nil && copied.validator(label, result.Distance)
+	if ok {
+		p.place(box(label))
+	}
+	label[0], label[1] = p.o.output(label[0], label[1])
+	return label, ok
+}
+
+// Placed returns the boxes of the labels placed so far, in order, in the
+// coordinates of the polygons.
+func (p *LabelPlacer) Placed() []Rect {
+	return append([]Rect(nil), p.placed...)
+}
+
+// the range of grid cells covered by a box
+func (p *LabelPlacer) cells(r Rect) (minI int, minJ int, maxI int, maxJ int) {
+	if !(p.cellSize > 0) {
+		return 0, 0, 0, 0
+	}
+	return int(math.Floor(r.MinX / p.cellSize)), int(math.Floor(r.MinY / p.cellSize)),
+		int(math.Floor(r.MaxX / p.cellSize)), int(math.Floor(r.MaxY / p.cellSize))
+}
+
+// report whether a box overlaps any placed box
+func (p *LabelPlacer) collides(r Rect) bool {
+	minI, minJ, maxI, maxJ := p.cells(r)
+	for i := minI; i <= maxI; i++ {
+		for j := minJ; j <= maxJ; j++ {
+			for _, n := range p.grid[[2]int{i, j}] {
+				if rectsOverlap(r, p.placed[n]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (p *LabelPlacer) place(r Rect) {
+	minI, minJ, maxI, maxJ := p.cells(r)
+	for i := minI; i <= maxI; i++ {
+		for j := minJ; j <= maxJ; j++ {
+			key := [2]int{i, j}
+			p.grid[key] = append(p.grid[key], len(p.placed))
+		}
+	}
+	p.placed = append(p.placed, r)
+}
+
+// report whether two boxes share more than an edge
+func rectsOverlap(a Rect, b Rect) bool {
+	return a.MinX < b.MaxX && b.MinX < a.MaxX && a.MinY < b.MaxY && b.MinY < a.MaxY
+}
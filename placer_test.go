@@ -0,0 +1,58 @@
+package polylabel
+
+import "testing"
+
+func TestLabelPlacer(t *testing.T) {
+	square := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	size := Coord{4, 2}
+	placer := NewLabelPlacer(4)
+
+	// the first label goes at the pole of inaccessibility
+	label, ok := placer.TryPlace(square, 0.1, size)
+	if !ok || label != (Coord{5, 5}) {
+		t.Errorf("Received %v, %v, expected the center of the square", label, ok)
+	}
+
+	// the second moves clear of it, staying inside the square
+	label, ok = placer.TryPlace(square, 0.1, size)
+	second := Rect{label[0] - 2, label[1] - 1, label[0] + 2, label[1] + 1}
+	if !ok || rectsOverlap(second, placer.Placed()[0]) || pointToPolygonDistance(label[0], label[1], square) <= 0 {
+		t.Errorf("Received %v, %v, expected a clear label in the square", label, ok)
+	}
+	// and is as far from the outline as it can be, above or below the first
+	if d := pointToPolygonDistance(label[0], label[1], square); d < 3-0.1 {
+		t.Errorf("Received %v at distance %v, expected a distance of about 3", label, d)
+	}
+
+	// a polygon covered by placed labels cannot be labelled
+	covered := Polygon{Ring{Coord{4, 4.5}, Coord{6, 4.5}, Coord{6, 5.5}, Coord{4, 5.5}, Coord{4, 4.5}}}
+	if label, ok = placer.TryPlace(covered, 0.1, size); ok {
+		t.Errorf("Received %v, %v, expected no placement", label, ok)
+	}
+	AssertEqual(t, len(placer.Placed()), 2)
+
+	// boxes may touch, and the grid is optional
+	for _, cellSize := range []float64{4, 0} {
+		placer = NewLabelPlacer(cellSize)
+		placer.TryPlace(square, 0.1, size)
+		AssertEqual(t, placer.collides(Rect{7, 4, 9, 6}), false)
+		AssertEqual(t, placer.collides(Rect{6.9, 4, 9, 6}), true)
+	}
+}
+
+func TestLabelPlacerOutputTransform(t *testing.T) {
+	// collisions are tested where the polygon is, not where the output goes
+	square := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	placer := NewLabelPlacer(4, WithOutputTransform(func(x, y float64) (float64, float64) {
+		return x * 100, y * 100
+	}), WithOutputRounding(0))
+	label, ok := placer.TryPlace(square, 0.1, Coord{4, 2})
+	if !ok || label != (Coord{500, 500}) {
+		t.Errorf("Received %v, %v, expected the transformed center of the square", label, ok)
+	}
+	label, ok = placer.TryPlace(square, 0.1, Coord{4, 2})
+	placed := placer.Placed()
+	if !ok || len(placed) != 2 || rectsOverlap(placed[0], placed[1]) || !(Rect{0, 0, 10, 10}).Contains(label[0]/100, label[1]/100) {
+		t.Errorf("Received %v, %v with boxes %v, expected a clear label in the square", label, ok, placed)
+	}
+}
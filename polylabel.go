@@ -1,173 +1,684 @@
-package main
-
-import (
-    "math"
-    "container/heap"
-)
-
-type Coord [2]float64
-type Ring []Coord
-type Polygon []Ring
-
-type Cell struct {
-    x float64
-    y float64
-    h float64
-    d float64
-    max float64
-}
-
-func NewCell(x float64, y float64, h float64, polygon Polygon) *Cell {
-    d := pointToPolygonDistance(x, y, polygon)
-    cell := Cell{x, y, h, d, d + h * math.Sqrt2}
-    return &cell
-}
-
-func NewCellItem(cell *Cell) *Item {
-    return &Item{cell, cell.d, 0}
-}
-
-func polylabel(polygon Polygon, precision float64) (float64, float64){
-    minX, minY, maxX, maxY := boundingBox(polygon)
-    
-    width := maxX - minX
-    height := maxY - minY
-    cellSize := math.Min(width, height)
-    h := cellSize / 2
-    
-    if cellSize == 0 {
-        return minX, minY
-    }
-    
-    cellQueue := make(PriorityQueue, 0)
-    
-    // cover polygon with initial cells
-    for x:= minX; x < maxX; x += cellSize {
-        for y := minY; y < maxY; y += cellSize {
-            heap.Push(&cellQueue, NewCellItem(NewCell(x + h, y + h, h, polygon)))
-        }
-    }
-    
-    // take centroid as the first best guess
-    bestCell := getCentroidCell(polygon)
-    
-    // special case for rectangular polygons
-    bboxCell := NewCell(minX + width / 2, minY + height / 2, 0, polygon)
-    if bboxCell.d > bestCell.d {
-        bestCell = bboxCell
-    }
-    
-    for cellQueue.Len() > 0 {
-        // pick the most promising cell from the queue
-        cellItem := heap.Pop(&cellQueue).(*Item)
-        cell := cellItem.value
-        
-        // update the best cell if we found a better one
-        if cell.d > bestCell.d {
-            bestCell = cell
-        }
-        
-        // do not drill down further if there's no chance of a better solution
-        if (cell.max - bestCell.d) <= precision {
-            continue
-        }
-        
-        // split the cell into four cells
-        h = cell.h / 2
-        heap.Push(&cellQueue, NewCellItem(NewCell(cell.x - h, cell.y - h, h, polygon)))
-        heap.Push(&cellQueue, NewCellItem(NewCell(cell.x + h, cell.y - h, h, polygon)))
-        heap.Push(&cellQueue, NewCellItem(NewCell(cell.x - h, cell.y + h, h, polygon)))
-        heap.Push(&cellQueue, NewCellItem(NewCell(cell.x + h, cell.y + h, h, polygon)))
-    }
-    
-    return bestCell.x, bestCell.y
-}
-
-func boundingBox(polygon Polygon) (minX float64, minY float64, maxX float64, maxY float64){
-    coords := polygon[0]
-    minX, minY = coords[0][0], coords[0][1]
-    maxX, maxY = coords[0][0], coords[0][1]
-    for _, coord := range coords {
-        x, y := coord[0], coord[1]
-        if x < minX {
-            minX = x
-        }
-        if x > maxX {
-            maxX = x
-        }
-        if y < minY {
-            minY = y
-        }
-        if y > maxY {
-            maxY = y
-        }
-    }
-    return
-}
-
-// signed distance from point to polygon outline (negative if point is outside)
-func pointToPolygonDistance(x float64, y float64, polygon Polygon) float64 {
-    inside := false
-    minDistSq := math.Inf(1)
-    
-    for _, ring := range polygon {
-        for n := 0; n < (len(ring) - 1); n++ {
-            a := ring[n]
-            b := ring[n + 1]
-            if (((a[1] > y) != (b[1] > y)) && (x < ((b[0] - a[0]) * (y - a[1]) / (b[1] - a[1]) + a[0]))) {
-                inside = !inside
-            }
-            minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
-        }
-    }
-    
-    factor := 1.0
-    if !inside {
-        factor = -1.0
-    }
-    return factor * math.Sqrt(minDistSq)
-}
-
-// get polygon centroid
-func getCentroidCell(polygon Polygon) *Cell {
-    area := 0.0
-    x := 0.0
-    y := 0.0
-    ring := polygon[0]
-    for n := 0; n < (len(ring) - 1); n++ {
-        a := ring[n]
-        b := ring[n + 1]
-        f := a[0] * b[1] - b[0] * a[1]
-        x += (a[0] + b[0]) * f
-        y += (a[1] + b[1]) * f
-        area += f * 3
-    }
-    if area == 0 {
-        return NewCell(ring[0][0], ring[0][1], 0, polygon)
-    }
-    return NewCell(x / area, y / area, 0, polygon)
-}
-
-// get squared distance from a point to a segment
-func segmentDistanceSquared(px float64, py float64, a [2]float64, b [2]float64) float64 {
-    x := a[0]
-    y := a[1]
-    dx := b[0] - x
-    dy := b[1] - y
-    
-    if dx != 0 || dy != 0 {
-        t := ((px - x) * dx + (py - y) * dy) / (dx * dx + dy * dy)
-        if t > 1 {
-            x = b[0]
-            y = b[1]
-        } else if t > 0 {
-            x += dx * t
-            y += dy * t
-        }
-    }
-    
-    dx = px - x
-    dy = py - y
-    
-    return dx * dx + dy * dy
-}
+// Package polylabel finds the pole of inaccessibility of a polygon: the
+// interior point farthest from the polygon outline. It is a port of the
+// polylabel algorithm from https://github.com/mapbox/polylabel and is
+// useful for placing a text label inside a polygon.
+//
+// The package level functions are safe for concurrent use. To reduce
+// allocations when labeling many polygons, reuse a Labeler, or a LabelerPool
+// when labeling from several goroutines.
+//
+// No function of the package modifies a polygon passed to it. Polygons it
+// returns may share rings with their input where those rings are unchanged,
+// as documented for each function, so use Polygon.Clone before modifying a
+// result in place.
+package polylabel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Coord is a point given as an [x, y] pair.
+type Coord [2]float64
+
+// Ring is a closed sequence of coordinates whose last coordinate repeats the
+// first.
+type Ring []Coord
+
+// Polygon is a list of rings. The first ring is the exterior and any further
+// rings are holes, following the GeoJSON convention.
+type Polygon []Ring
+
+// Clone returns a deep copy of polygon, sharing no rings with it, so that
+// either can be modified without affecting the other. A nil polygon gives nil.
+func (polygon Polygon) Clone() Polygon {
+	if polygon == nil {
+		return nil
+	}
+	cloned := make(Polygon, len(polygon))
+	for i, ring := range polygon {
+		if ring != nil {
+			cloned[i] = make(Ring, len(ring))
+			copy(cloned[i], ring)
+		}
+	}
+	return cloned
+}
+
+type cell struct {
+	x   float64
+	y   float64
+	h   float64
+	d   float64
+	max float64
+}
+
+// a cell with distance d from the polygon outline at its center, which no
+// point of the cell can exceed by more than its half diagonal
+func newCell(x float64, y float64, h float64, d float64) cell {
+	return cell{x, y, h, d, d + h*math.Sqrt2}
+}
+
+// Result holds the label position along with the values used to choose it.
+type Result struct {
+	X float64
+	Y float64
+	// Distance from the label to the nearest point of the polygon outline,
+	// in the units of the input coordinates. It is negative if the label
+	// lies outside the polygon.
+	Distance float64
+	// Score is Distance relative to half the shortest side of the bounding
+	// box, clamped to [0, 1]. It gives a comparable measure of how much room
+	// a polygon has for a label regardless of its size.
+	Score float64
+	// Tangents are the points where the largest circle around the label
+	// touches the polygon outline, to within precision. They are only
+	// computed when WithTangentPoints is enabled.
+	Tangents []Coord
+	// Corners are the distances at the corners of the final cell of the
+	// search, in the order bottom left, bottom right, top left, top right.
+	// They are only computed when WithRefinement is enabled.
+	Corners [4]float64
+	// Aspect is the ratio of the width to the height of the free space
+	// through the label, or 0 if the label is not inside the polygon. It is
+	// only computed when WithAspectRatio is enabled.
+	Aspect float64
+	// Simplified reports whether the polygon was simplified to meet the
+	// budget set by WithMaxVertices.
+	Simplified bool
+	// Rejected reports that the validator set by WithResultValidator
+	// rejected every candidate, in which case the label is the best of the
+	// rejected ones.
+	Rejected bool
+}
+
+// Polylabel returns the pole of inaccessibility of polygon.
+//
+// Rings whose last coordinate does not repeat the first are closed, so a ring
+// of three distinct coordinates is a triangle, while a ring of three
+// coordinates that returns to its start is a line with no area. A polygon
+// whose bounding box has no width or no height, such as a vertical or
+// horizontal line, is labelled at the center of its bounding box, which is
+// the middle of the line.
+//
+// precision is the tolerance of the search in the units of the input
+// coordinates: the distance of the returned point from the outline is within
+// precision of the best achievable. Smaller values give more accurate results
+// at the cost of more work. If precision is at least the shorter side of the
+// bounding box, no search is done and the better of the centroid and the
+// bounding box center is returned. This includes any huge or infinite
+// precision, so raising precision to save work ends in this coarsest label.
+func Polylabel(polygon Polygon, precision float64, opts ...Option) (float64, float64) {
+	result := PolylabelVerbose(polygon, precision, opts...)
+	return result.X, result.Y
+}
+
+// PolylabelVerbose is like Polylabel but also reports the distance of the
+// label from the polygon outline and a normalized score.
+func PolylabelVerbose(polygon Polygon, precision float64, opts ...Option) Result {
+	search := NewSearch(polygon, precision, opts...)
+	search.Run(0)
+	return search.Result()
+}
+
+// ErrNoLabel is returned by PolylabelAtLeast when a polygon has no usable
+// label.
+var ErrNoLabel = errors.New("polylabel: no usable label")
+
+// PolylabelAtLeast is like PolylabelVerbose but returns ErrNoLabel if there
+// is no usable label: if the polygon is empty or has no area, or the label is
+// not inside it, or is less than minDistance from the outline, or every
+// candidate was rejected by the validator set by WithResultValidator. The
+// label found is returned along with ErrNoLabel. This saves filtering out
+// unusable labels after the fact when labelling many polygons.
+func PolylabelAtLeast(polygon Polygon, precision float64, minDistance float64, opts ...Option) (Result, error) {
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return Result{}, ErrNoLabel
+	}
+	result := PolylabelVerbose(polygon, precision, opts...)
+	if !(result.Distance > 0) || !(result.Distance >= minDistance) || result.Rejected {
+		return result, ErrNoLabel
+	}
+	return result, nil
+}
+
+// PolylabelRing labels a single ring of polygon, treating it as a solid shape
+// and ignoring the other rings. This picks out one of the shapes of a polygon
+// whose rings are separate shapes, as with WithRingsAsSeparate. Edge weights
+// for the ring are taken from weights[ringIndex]. It is an error if the ring
+// index is out of range.
+func PolylabelRing(polygon Polygon, ringIndex int, precision float64, opts ...Option) (Result, error) {
+	if ringIndex < 0 || ringIndex >= len(polygon) {
+		return Result{}, fmt.Errorf("polylabel: ring index %d out of range for a polygon with %d rings", ringIndex, len(polygon))
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		if ringIndex < len(o.edgeWeights) {
+			o.edgeWeights = o.edgeWeights[ringIndex : ringIndex+1]
+		} else if o.edgeWeights != nil {
+			o.edgeWeights = [][]float64{}
+		}
+	})
+	return PolylabelVerbose(Polygon{polygon[ringIndex]}, precision, opts...), nil
+}
+
+// evaluates the cell centered on x, y with half size h
+type cellFunc func(x float64, y float64, h float64) cell
+
+// state of a branch and bound search over cells covering the bounding box
+// for the cell with the greatest distance
+type cellSearch struct {
+	queue     cellQueue
+	best      cell
+	precision float64
+	center    Coord // point preferred between tied cells
+	cellAt    cellFunc
+	o         *options
+	stats     Stats
+	fallback  cell   // best cell rejected by the result validator
+	near      []cell // cells within precision of the best for WithTieBreakToward
+
+	// progress for WithRelativeStop
+	steps     int
+	lastGain  int     // step at which the best distance last improved enough
+	reference float64 // best distance at lastGain
+}
+
+// cover the bounding box with initial cells, starting from an initial best
+// guess; the queue is built in scratch, which may be nil
+func newCellSearch(minX float64, minY float64, maxX float64, maxY float64, precision float64, bestCell cell, cellAt cellFunc, o *options, scratch cellQueue) *cellSearch {
+	cellSize := math.Min(maxX-minX, maxY-minY)
+	h := cellSize / 2
+
+	if scratch == nil {
+		scratch = make(cellQueue, 0, estimateQueueSize(maxX-minX, maxY-minY, precision))
+	}
+	s := &cellSearch{
+		queue:     scratch[:0],
+		best:      bestCell,
+		precision: precision,
+		center:    o.tieCenter(minX, minY, maxX, maxY),
+		cellAt:    cellAt,
+		o:         o,
+	}
+
+	// cover polygon with initial cells, counting them up front so that
+	// rounding in a running sum cannot add or drop a row or column
+	columns := cellCount(maxX-minX, cellSize)
+	rows := cellCount(maxY-minY, cellSize)
+	for i := 0; i < columns; i++ {
+		x := minX + float64(i)*cellSize
+		for j := 0; j < rows; j++ {
+			y := minY + float64(j)*cellSize
+			s.queue.push(cellAt(x+h, y+h, h), o.greedy)
+		}
+	}
+	s.limitQueue()
+	s.stats.PeakQueue = len(s.queue)
+
+	return s
+}
+
+// get the number of cells of size cellSize needed to cover length, ignoring
+// rounding error that would otherwise add a sliver of a cell
+func cellCount(length float64, cellSize float64) int {
+	n := math.Ceil(length/cellSize - 1e-9)
+	if n < 1 {
+		return 1
+	}
+	return int(n)
+}
+
+// process the most promising cell in the queue, returning false once the queue
+// is exhausted
+func (s *cellSearch) step() bool {
+	if len(s.queue) == 0 {
+		return false
+	}
+	if s.o.stopWindow > 0 && s.stalled() {
+		s.queue = s.queue[:0]
+		return false
+	}
+
+	// pick the most promising cell from the queue
+	c := s.queue.pop(s.o.greedy)
+
+	// update the best cell if we found a better one
+	improved, rejected := s.better(c)
+	if improved {
+		s.best = c
+	}
+	if s.o.tieBreak {
+		s.keepNear(c, improved)
+	}
+
+	// do not drill down further if there's no chance of a better solution
+	if (c.max - s.best.d) <= s.precision {
+		return true
+	}
+
+	// or if no point in it is far enough from the outline for
+	// WithMinCorridorWidth
+	if c.max < s.o.minCorridor/2 {
+		return true
+	}
+
+	// until the validator accepts a cell there is no best to prune against,
+	// and a rejected cell deeper than the best never falls within precision
+	// of it, nor does a cell whose distance is beyond its compactness score,
+	// so stop at cells as small as precision
+	if (rejected || math.IsInf(s.best.d, -1) || s.o.compactness > 0) && 2*c.h <= s.precision {
+		return true
+	}
+
+	// or if the children could not be told apart in float32
+	if s.o.float32Output && c.h/2 < math.Max(float32ULP(c.x), float32ULP(c.y)) {
+		return true
+	}
+
+	// or if their centers would fall between the points of the fixed grid
+	// of PolylabelInteger
+	if c.h/2 < s.o.grid {
+		return true
+	}
+
+	// or if their distances would be estimated for WithApproximateBelow,
+	// which cannot improve on the best
+	if c.h < s.o.approxBelow {
+		return true
+	}
+
+	// split the cell into four cells
+	for _, child := range splitCell(c, s.cellAt, s.o.parallel) {
+		s.queue.push(child, s.o.greedy)
+	}
+	s.stats.Subdivisions++
+	s.limitQueue()
+	if len(s.queue) > s.stats.PeakQueue {
+		s.stats.PeakQueue = len(s.queue)
+	}
+	return true
+}
+
+// keep the queue within the size set by WithMaxQueueSize
+func (s *cellSearch) limitQueue() {
+	if s.o.maxQueue > 0 {
+		s.stats.Discarded += s.queue.truncate(s.o.maxQueue, s.o.greedy)
+	}
+}
+
+// report whether the best distance has failed to improve on the last
+// reference by more than the relative stop fraction for a whole window of
+// steps
+func (s *cellSearch) stalled() bool {
+	if s.steps == 0 || s.best.d > s.reference+s.o.stopFraction*math.Abs(s.reference) {
+		s.reference = s.best.d
+		s.lastGain = s.steps
+	}
+	s.steps++
+	return s.steps-1-s.lastGain >= s.o.stopWindow
+}
+
+// a placeholder best cell that any cell improves on
+var noCell = cell{d: math.Inf(-1), max: math.Inf(-1)}
+
+// report whether c improves on the best cell and is accepted by the result
+// validator, or else whether it would have improved but was rejected, keeping
+// the best of the rejected cells as a fallback
+func (s *cellSearch) better(c cell) (improved bool, rejected bool) {
+	if !s.improves(c, s.best) {
+		return false, false
+	}
+	if !s.o.accepts(c) {
+		if s.improves(c, s.fallback) {
+			s.fallback = c
+		}
+		return false, true
+	}
+	return true, false
+}
+
+// keep c if it is within precision of the best cell and accepted by the
+// result validator, dropping the cells left behind if the best improved
+func (s *cellSearch) keepNear(c cell, improved bool) {
+	if improved {
+		kept := s.near[:0]
+		for _, n := range s.near {
+			if n.d >= s.best.d-s.precision {
+				kept = append(kept, n)
+			}
+		}
+		s.near = append(kept, c)
+		return
+	}
+	if c.d >= s.best.d-s.precision && s.o.accepts(c) {
+		s.near = append(s.near, c)
+	}
+}
+
+// get the cell closest to the tie-break reference of those within precision
+// of the best cell
+func (s *cellSearch) nearest() cell {
+	best := s.best
+	for _, n := range s.near {
+		if n.d >= s.best.d-s.precision && squaredDistance(n.x, n.y, s.center) < squaredDistance(best.x, best.y, s.center) {
+			best = n
+		}
+	}
+	return best
+}
+
+// replace the initial best cell with noCell if the result validator rejects it
+func (s *cellSearch) validateBest() {
+	s.fallback = noCell
+	if !s.o.accepts(s.best) {
+		s.fallback, s.best = s.best, noCell
+	}
+}
+
+// report whether c improves on other; on a tie the cell closest to the
+// bounding box center wins, so symmetric polygons are labelled at their
+// center of symmetry rather than wherever float ordering lands, unless
+// WithTieBreakToward moves the center to its reference
+func (s *cellSearch) improves(c cell, other cell) bool {
+	if c.d != other.d {
+		return c.d > other.d
+	}
+	return squaredDistance(c.x, c.y, s.center) < squaredDistance(other.x, other.y, s.center)
+}
+
+// get the squared distance between a point and a coordinate
+func squaredDistance(x, y float64, p Coord) float64 {
+	dx, dy := x-p[0], y-p[1]
+	// rounding each product stops them being fused with the sum, which
+	// would let ties be decided differently on different architectures
+	return float64(dx*dx) + float64(dy*dy)
+}
+
+// run a search to completion, returning the best cell found
+func searchCells(minX float64, minY float64, maxX float64, maxY float64, precision float64, bestCell cell, cellAt cellFunc, o *options) cell {
+	s := newCellSearch(minX, minY, maxX, maxY, precision, bestCell, cellAt, o, nil)
+	for s.step() {
+	}
+	return s.best
+}
+
+// split a cell into its four quadrants, evaluating them concurrently if
+// parallel is set; the quadrants are always returned in the same order so the
+// result does not depend on scheduling
+func splitCell(c cell, cellAt cellFunc, parallel bool) [4]cell {
+	h := c.h / 2
+	centers := [4]Coord{
+		{c.x - h, c.y - h},
+		{c.x + h, c.y - h},
+		{c.x - h, c.y + h},
+		{c.x + h, c.y + h},
+	}
+	var children [4]cell
+	if !parallel {
+		for i, center := range centers {
+			children[i] = cellAt(center[0], center[1], h)
+		}
+		return children
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(centers))
+	for i, center := range centers {
+		go func(i int, center Coord) {
+			defer wg.Done()
+			children[i] = cellAt(center[0], center[1], h)
+		}(i, center)
+	}
+	wg.Wait()
+	return children
+}
+
+// ratio of the distance to half the shortest bounding box side, clamped to [0, 1]
+func labelScore(distance float64, cellSize float64) float64 {
+	// half of the smallest subnormal is zero, which would give NaN
+	if cellSize/2 == 0 {
+		return 0
+	}
+	score := distance / (cellSize / 2)
+	return math.Max(0, math.Min(1, score))
+}
+
+// the bounding box of the exterior ring, which contains the holes
+func boundingBox(polygon Polygon) (minX float64, minY float64, maxX float64, maxY float64) {
+	return ringsBoundingBox(polygon[:1])
+}
+
+// the bounding box of all coordinates of a set of rings
+func ringsBoundingBox(rings []Ring) (minX float64, minY float64, maxX float64, maxY float64) {
+	minX, minY = rings[0][0][0], rings[0][0][1]
+	maxX, maxY = rings[0][0][0], rings[0][0][1]
+	for _, coords := range rings {
+		for _, coord := range coords {
+			x, y := coord[0], coord[1]
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+		}
+	}
+	return
+}
+
+// signed distance from point to polygon outline (negative if point is outside)
+func pointToPolygonDistance(x float64, y float64, polygon Polygon) float64 {
+	inside := false
+	minDistSq := math.Inf(1)
+
+	for _, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			a := ring[n]
+			b := ring[n+1]
+			if rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
+		}
+	}
+
+	factor := 1.0
+	if !inside {
+		factor = -1.0
+	}
+	return factor * math.Sqrt(minDistSq)
+}
+
+// areas smaller than this fraction of the summed magnitudes of their terms are
+// dominated by rounding error and treated as zero, as are areas that are NaN
+const centroidAreaEpsilon = 1e-8
+
+// signed distance from point to the outline of a set of rings that are each
+// separate solid shapes (negative if point is outside all of them)
+func pointToRingsDistance(x float64, y float64, rings []Ring) float64 {
+	insideAny := false
+	minDistSq := math.Inf(1)
+
+	for _, ring := range rings {
+		inside := false
+		for n := 0; n < (len(ring) - 1); n++ {
+			a := ring[n]
+			b := ring[n+1]
+			if rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
+		}
+		insideAny = insideAny || inside
+	}
+
+	factor := 1.0
+	if !insideAny {
+		factor = -1.0
+	}
+	return factor * math.Sqrt(minDistSq)
+}
+
+// signed distance from point to polygon outline (negative if point is
+// outside), where a point is inside if the rings wind around it more times in
+// the direction of the exterior ring than against it
+func pointToPolygonDistanceWinding(x float64, y float64, polygon Polygon) float64 {
+	winding := 0
+	minDistSq := math.Inf(1)
+
+	for _, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			a := ring[n]
+			b := ring[n+1]
+			side := (b[0]-a[0])*(y-a[1]) - (x-a[0])*(b[1]-a[1])
+			if a[1] <= y {
+				if b[1] > y && side > 0 {
+					winding++
+				}
+			} else if b[1] <= y && side < 0 {
+				winding--
+			}
+			minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
+		}
+	}
+
+	if ringArea(polygon[0]) < 0 {
+		winding = -winding
+	}
+	factor := 1.0
+	if winding <= 0 {
+		factor = -1.0
+	}
+	return factor * math.Sqrt(minDistSq)
+}
+
+// twice the signed area of a ring, positive if it is counter-clockwise
+func ringArea(ring Ring) float64 {
+	area := 0.0
+	for n := 0; n < (len(ring) - 1); n++ {
+		a := ring[n]
+		b := ring[n+1]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area
+}
+
+// whether a ray cast from a point in the +x direction crosses a segment
+func rayCrosses(x float64, y float64, a [2]float64, b [2]float64) bool {
+	return ((a[1] > y) != (b[1] > y)) && (x < ((b[0]-a[0])*(y-a[1])/(b[1]-a[1]) + a[0]))
+}
+
+// CentroidInside reports whether the centroid of the exterior ring of
+// polygon, which seeds the search, is strictly inside polygon. It is not for
+// concave polygons such as a U shape, or where a hole covers it, in which
+// case the search replaces it with the best cell found. An empty polygon has
+// no centroid inside it.
+func CentroidInside(polygon Polygon) bool {
+	polygon = closeRings(polygon)
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return false
+	}
+	cx, cy := getCentroid(polygon)
+	return pointToPolygonDistance(cx, cy, polygon) > 0
+}
+
+// get polygon centroid
+func getCentroid(polygon Polygon) (float64, float64) {
+	area := 0.0
+	areaMagnitude := 0.0
+	x := 0.0
+	y := 0.0
+	ring := polygon[0]
+
+	// work relative to the first vertex and in units of the size of the ring,
+	// so that the products can neither overflow for huge coordinates nor
+	// cancel out for coordinates far from the origin
+	originX, originY := ring[0][0], ring[0][1]
+	minX, minY, maxX, maxY := boundingBox(polygon)
+	scale := math.Max(maxX-minX, maxY-minY)
+	if scale == 0 || math.IsInf(scale, 0) {
+		return originX, originY
+	}
+
+	// an open ring is closed by the edge from its last vertex back to its
+	// first, which adds nothing if the ring is already closed
+	for n := range ring {
+		next := ring[(n+1)%len(ring)]
+		ax, ay := (ring[n][0]-originX)/scale, (ring[n][1]-originY)/scale
+		bx, by := (next[0]-originX)/scale, (next[1]-originY)/scale
+		f := ax*by - bx*ay
+		x += (ax + bx) * f
+		y += (ay + by) * f
+		area += f * 3
+		areaMagnitude += math.Abs(f * 3)
+	}
+	if !(math.Abs(area) > centroidAreaEpsilon*areaMagnitude) {
+		return originX, originY
+	}
+	return originX + x/area*scale, originY + y/area*scale
+}
+
+// get squared distance from a point to a segment
+func segmentDistanceSquared(px float64, py float64, a [2]float64, b [2]float64) float64 {
+	x, y := segmentClosestPoint(px, py, a, b)
+	dx := px - x
+	dy := py - y
+
+	return dx*dx + dy*dy
+}
+
+// get the point on a segment closest to a point
+func segmentClosestPoint(px float64, py float64, a [2]float64, b [2]float64) (float64, float64) {
+	x := a[0]
+	y := a[1]
+	dx := b[0] - x
+	dy := b[1] - y
+
+	if dx != 0 || dy != 0 {
+		t := ((px-x)*dx + (py-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x = b[0]
+			y = b[1]
+		} else if t > 0 {
+			x += dx * t
+			y += dy * t
+		}
+	}
+
+	return x, y
+}
+
+// get the points of the polygon outline whose distance from a point is within
+// tolerance of the nearest, i.e. where a circle around the point touches it
+func tangentPoints(x float64, y float64, polygon Polygon, tolerance float64) []Coord {
+	minDist := math.Abs(pointToPolygonDistance(x, y, polygon))
+	var points []Coord
+	for _, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			px, py := segmentClosestPoint(x, y, ring[n], ring[n+1])
+			if math.Hypot(px-x, py-y)-minDist > tolerance {
+				continue
+			}
+			point := Coord{px, py}
+			// adjacent segments share their end points
+			duplicate := false
+			for _, p := range points {
+				if p == point {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				points = append(points, point)
+			}
+		}
+	}
+	return points
+}
@@ -1,68 +1,898 @@
-package main
-
-import (
-    "testing"
-    "os"
-    "encoding/json"
-    "io/ioutil"
-	"reflect"
-)
-
-func AssertEqual(t *testing.T, a interface{}, b interface{}) {
-	if a == b {
-		return
-	}
-	t.Errorf("Received %v (type %v), expected %v (type %v)", a, reflect.TypeOf(a), b, reflect.TypeOf(b))
-}
-
-func loadData(filename string) (polygon Polygon) {
-    jsonFile, err := os.Open(filename)
-    if err != nil {
-        panic("failed to open json file")
-    }
-    defer jsonFile.Close()
-    
-    byteValue, _ := ioutil.ReadAll(jsonFile)
-    
-    err = json.Unmarshal(byteValue, &polygon)
-    if err != nil {
-        panic("failed to parse json file")
-    }
-    
-    return polygon
-}
-
-func TestPolylabelWater1(t *testing.T) {
-    polygon := loadData("test_data/water1.json")
-    var x, y float64
-    
-    x, y = polylabel(polygon, 1.0)
-    AssertEqual(t, x, 3865.85009765625)
-    AssertEqual(t, y, 2124.87841796875)
-    
-    x, y = polylabel(polygon, 50.0)
-    AssertEqual(t, x, 3854.296875)
-    AssertEqual(t, y, 2123.828125)
-}
-
-func TestPolylabelWater2(t *testing.T) {
-    polygon := loadData("test_data/water2.json")
-    
-    x, y := polylabel(polygon, 1.0)
-    AssertEqual(t, x, 3263.5)
-    AssertEqual(t, y, 3263.5)
-}
-
-func TestDegeneratePolygons(t *testing.T) {
-    var x, y float64
-    
-    polygon := Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{2, 0}, Coord{0, 0}}}
-    x, y = polylabel(polygon, 1.0)
-    AssertEqual(t, x, 0.0)
-    AssertEqual(t, y, 0.0)
-    
-    polygon = Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{1, 1}, Coord{1, 0}, Coord{0, 0}}}
-    x, y = polylabel(polygon, 1.0)
-    AssertEqual(t, x, 0.0)
-    AssertEqual(t, y, 0.0)
-}
+package polylabel
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func AssertEqual(t *testing.T, a interface{}, b interface{}) {
+	if a == b {
+		return
+	}
+	t.Errorf("Received %v (type %v), expected %v (type %v)", a, reflect.TypeOf(a), b, reflect.TypeOf(b))
+}
+
+func loadData(filename string) (polygon Polygon) {
+	jsonFile, err := os.Open(filename)
+	if err != nil {
+		panic("failed to open json file")
+	}
+	defer jsonFile.Close()
+
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	err = json.Unmarshal(byteValue, &polygon)
+	if err != nil {
+		panic("failed to parse json file")
+	}
+
+	return polygon
+}
+
+func TestPolylabelWater1(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	var x, y float64
+
+	x, y = Polylabel(polygon, 1.0)
+	AssertEqual(t, x, 3865.85009765625)
+	AssertEqual(t, y, 2124.87841796875)
+
+	x, y = Polylabel(polygon, 50.0)
+	AssertEqual(t, x, 3854.296875)
+	AssertEqual(t, y, 2123.828125)
+}
+
+func TestPolylabelWater2(t *testing.T) {
+	polygon := loadData("test_data/water2.json")
+
+	x, y := Polylabel(polygon, 1.0)
+	AssertEqual(t, x, 3263.5)
+	AssertEqual(t, y, 3263.5)
+}
+
+func TestDegeneratePolygons(t *testing.T) {
+	var x, y float64
+
+	// a horizontal line is labelled at its middle
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{2, 0}, Coord{0, 0}}}
+	x, y = Polylabel(polygon, 1.0)
+	AssertEqual(t, x, 1.0)
+	AssertEqual(t, y, 0.0)
+
+	// as is a vertical one
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{0, 5}, Coord{0, 10}, Coord{0, 0}}}
+	result := PolylabelVerbose(polygon, 1.0)
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{0, 5})
+	AssertEqual(t, result.Distance, 0.0)
+	point, _ := InitialEstimate(polygon)
+	AssertEqual(t, point, Coord{0, 5})
+	result = PolylabelRect(polygon, 1.0, 2.0)
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{0, 5})
+	x, y = Polylabel(Polygon{Ring{Coord{-3, 7}, Coord{5, 7}}}, 1.0)
+	AssertEqual(t, Coord{x, y}, Coord{1, 7})
+
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{1, 1}, Coord{1, 0}, Coord{0, 0}}}
+	x, y = Polylabel(polygon, 1.0)
+	AssertEqual(t, x, 0.0)
+	AssertEqual(t, y, 0.0)
+}
+
+func TestWithoutBBoxCell(t *testing.T) {
+	polygon := loadData("test_data/water2.json")
+
+	x, y := Polylabel(polygon, 1.0, WithBBoxCell(false))
+	AssertEqual(t, x, 3263.5)
+	AssertEqual(t, y, 3263.5)
+
+	// a square is labelled at its center by the grid search alone
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	x, y = Polylabel(polygon, 1.0, WithBBoxCell(false))
+	AssertEqual(t, x, 2.0)
+	AssertEqual(t, y, 2.0)
+
+	// the pole of this notched kite is the bounding box center, which lies on
+	// the boundary between the initial cells and so is never a cell center;
+	// only the bounding box cell finds it, and without it the search settles
+	// for the centroid
+	polygon = Polygon{Ring{Coord{0, 5}, Coord{10, 0}, Coord{20, 5}, Coord{16, 6}, Coord{14, 8}, Coord{10, 10}, Coord{0, 5}}}
+	x, y = Polylabel(polygon, 1.0)
+	AssertEqual(t, x, 10.0)
+	AssertEqual(t, y, 5.0)
+	cx, cy := getCentroid(polygon)
+	x, y = Polylabel(polygon, 1.0, WithBBoxCell(false))
+	AssertEqual(t, x, cx)
+	AssertEqual(t, y, cy)
+}
+
+func TestPolylabelVerbose(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	result := PolylabelVerbose(polygon, 1.0)
+	AssertEqual(t, result.X, 2.0)
+	AssertEqual(t, result.Y, 2.0)
+	AssertEqual(t, result.Distance, 2.0)
+	AssertEqual(t, result.Score, 1.0)
+
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{0, 4}, Coord{0, 0}}}
+	result = PolylabelVerbose(polygon, 0.01)
+	if result.Score <= 0 || result.Score >= 1 {
+		t.Errorf("Expected score in (0, 1), received %v", result.Score)
+	}
+
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{2, 0}, Coord{0, 0}}}
+	result = PolylabelVerbose(polygon, 1.0)
+	AssertEqual(t, result.Score, 0.0)
+}
+
+func TestParallelMatchesSerial(t *testing.T) {
+	for _, filename := range []string{"test_data/water1.json", "test_data/water2.json"} {
+		polygon := loadData(filename)
+		for _, precision := range []float64{1.0, 50.0} {
+			serial := PolylabelVerbose(polygon, precision)
+			parallel := PolylabelVerbose(polygon, precision, WithParallel(true))
+			if !reflect.DeepEqual(parallel, serial) {
+				t.Errorf("Received %v from parallel search, expected %v", parallel, serial)
+			}
+		}
+	}
+}
+
+func TestTangentPoints(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+
+	result := PolylabelVerbose(polygon, 1.0)
+	AssertEqual(t, len(result.Tangents), 0)
+
+	result = PolylabelVerbose(polygon, 1.0, WithTangentPoints(true))
+	expected := []Coord{{2, 0}, {4, 2}, {2, 4}, {0, 2}}
+	if !reflect.DeepEqual(result.Tangents, expected) {
+		t.Errorf("Received %v, expected %v", result.Tangents, expected)
+	}
+
+	// a rectangle only touches the circle on its long sides
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{0, 4}, Coord{0, 0}}}
+	result = PolylabelVerbose(polygon, 0.1, WithTangentPoints(true))
+	expected = []Coord{{5, 0}, {5, 4}}
+	if !reflect.DeepEqual(result.Tangents, expected) {
+		t.Errorf("Received %v, expected %v", result.Tangents, expected)
+	}
+}
+
+func TestCentroidOfSliver(t *testing.T) {
+	// the area of this sliver used to be lost to rounding error, which placed
+	// the centroid hundreds of units away from it
+	polygon := Polygon{Ring{Coord{1e6, 1e6}, Coord{1e6 + 1, 1e6 + 0.01}, Coord{1e6 + 2, 1e6}, Coord{1e6, 1e6}}}
+	x, y := getCentroid(polygon)
+	if math.Abs(x-(1e6+1)) > 1e-6 || math.Abs(y-(1e6+0.01/3)) > 1e-6 {
+		t.Errorf("Received %v, %v, expected the centroid of the sliver", x, y)
+	}
+
+	// well conditioned polygons are unaffected
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	x, y = getCentroid(polygon)
+	AssertEqual(t, x, 2.0)
+	AssertEqual(t, y, 2.0)
+}
+
+func TestCentroidOfNearCoincidentVertices(t *testing.T) {
+	// a ring that retraces itself, as a transform can leave one, with
+	// vertices a unit in the last place from those they double back over; its
+	// area is lost to rounding error and its centroid could be anywhere
+	x0, y0 := 1e6, 1e6
+	up := math.Nextafter(x0+1, 2e6)
+	polygon := Polygon{Ring{
+		Coord{x0, y0}, Coord{x0 + 1, y0}, Coord{x0 + 1, y0 + 1}, Coord{x0, y0 + 1},
+		Coord{x0, up}, Coord{up, y0 + 1}, Coord{up, y0}, Coord{x0, y0},
+	}}
+	x, y := getCentroid(polygon)
+	AssertEqual(t, x, x0)
+	AssertEqual(t, y, y0)
+
+	// an area that is NaN falls back to the first vertex too
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{math.NaN(), 4}, Coord{0, 4}, Coord{0, 0}}}
+	x, y = getCentroid(polygon)
+	AssertEqual(t, x, 0.0)
+	AssertEqual(t, y, 0.0)
+}
+
+func TestRect(t *testing.T) {
+	r := Rect{0, 0, 4, 2}
+	AssertEqual(t, r.Contains(2, 1), true)
+	AssertEqual(t, r.Contains(4, 2), true)
+	AssertEqual(t, r.Contains(4.5, 1), false)
+	AssertEqual(t, r.Intersects(Rect{3, 1, 5, 3}), true)
+	AssertEqual(t, r.Intersects(Rect{4, 2, 5, 3}), true)
+	AssertEqual(t, r.Intersects(Rect{4.5, 0, 5, 2}), false)
+	AssertEqual(t, r.Intersects(Rect{-1, -1, 5, 3}), true)
+
+	// the polygon of a rectangle is labelled at its center and bounded by it
+	polygon := r.ToPolygon()
+	AssertEqual(t, polygon.Bounds(), r)
+	AssertEqual(t, ringArea(polygon[0]) > 0, true)
+	x, y := Polylabel(polygon, 0.1)
+	AssertEqual(t, x, 2.0)
+	AssertEqual(t, y, 1.0)
+	AssertEqual(t, Polygon(nil).Bounds(), Rect{})
+}
+
+func TestPolylabelRect(t *testing.T) {
+	// a rectangle with the requested aspect fills the polygon exactly
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{0, 4}, Coord{0, 0}}}
+	result := PolylabelRect(polygon, 0.1, 2.5)
+	AssertEqual(t, result.X, 5.0)
+	AssertEqual(t, result.Y, 2.0)
+	AssertEqual(t, result.Distance, 2.0)
+	AssertEqual(t, result.Score, 1.0)
+
+	// an open ring is closed, so that its closing edge still bounds the
+	// rectangle
+	open := Polygon{polygon[0][1:]}
+	expected := PolylabelRect(polygon, 0.1, 1)
+	if received := PolylabelRect(open, 0.1, 1); !reflect.DeepEqual(received, expected) {
+		t.Errorf("Received %v, expected %v", received, expected)
+	}
+
+	// a wide label fits in the long arm of an L rather than the square
+	// corner preferred by the largest inscribed circle
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{20, 0}, Coord{20, 2}, Coord{3, 2}, Coord{3, 5}, Coord{0, 5}, Coord{0, 0}}}
+	result = PolylabelRect(polygon, 0.01, 5)
+	if result.Y > 2 || math.Abs(result.Distance-1) > 0.01 {
+		t.Errorf("Received %v, expected a rectangle of half height 1 in the long arm", result)
+	}
+}
+
+func TestTriangle(t *testing.T) {
+	// a 3-4-5 right triangle has its incenter at (1, 1) with inradius 1
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{0, 3}, Coord{0, 0}}}
+	for _, precision := range []float64{0.1, 0.01, 0.001} {
+		result := PolylabelVerbose(polygon, precision)
+		if result.Distance < 1-precision || result.Distance > 1 {
+			t.Errorf("Received distance %v at precision %v, expected within precision of 1", result.Distance, precision)
+		}
+		// a circle of radius 1 - precision fits around points up to
+		// sqrt(10) * precision from the incenter in this triangle
+		if math.Hypot(result.X-1, result.Y-1) > math.Sqrt(10)*precision {
+			t.Errorf("Received %v, %v at precision %v, expected close to the incenter", result.X, result.Y, precision)
+		}
+	}
+}
+
+func TestWithOutputRounding(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	var x, y float64
+
+	x, y = Polylabel(polygon, 1.0, WithOutputRounding(0))
+	AssertEqual(t, x, 3866.0)
+	AssertEqual(t, y, 2125.0)
+
+	x, y = Polylabel(polygon, 1.0, WithOutputRounding(1))
+	AssertEqual(t, x, 3865.9)
+	AssertEqual(t, y, 2124.9)
+
+	x, y = Polylabel(polygon, 1.0, WithOutputRounding(-2))
+	AssertEqual(t, x, 3900.0)
+	AssertEqual(t, y, 2100.0)
+}
+
+func TestWindingContainment(t *testing.T) {
+	exterior := Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}
+	// two clockwise holes overlapping on 4 <= x <= 6
+	left := Ring{Coord{1, 1}, Coord{1, 9}, Coord{6, 9}, Coord{6, 1}, Coord{1, 1}}
+	right := Ring{Coord{4, 1}, Coord{4, 9}, Coord{9, 9}, Coord{9, 1}, Coord{4, 1}}
+	polygon := Polygon{exterior, left, right}
+
+	// with even-odd the overlap of the holes counts as inside
+	d := pointToPolygonDistance(5, 5, polygon)
+	if d <= 0 {
+		t.Errorf("Received %v, expected the overlap of the holes to be inside", d)
+	}
+	d = pointToPolygonDistanceWinding(5, 5, polygon)
+	if d >= 0 {
+		t.Errorf("Received %v, expected the overlap of the holes to be outside", d)
+	}
+
+	// the result is the same for a clockwise exterior with counter-clockwise
+	// holes
+	reversed := make(Polygon, len(polygon))
+	for i, ring := range polygon {
+		for j := len(ring) - 1; j >= 0; j-- {
+			reversed[i] = append(reversed[i], ring[j])
+		}
+	}
+	AssertEqual(t, pointToPolygonDistanceWinding(5, 5, reversed), d)
+	AssertEqual(t, pointToPolygonDistanceWinding(0.5, 5, reversed), 0.5)
+
+	// only the margin around the holes is left for the label
+	result := PolylabelVerbose(polygon, 0.01, WithContainmentRule(Winding))
+	if result.Distance > 0.6 {
+		t.Errorf("Received %v, expected a label in the margin", result)
+	}
+	result = PolylabelVerbose(polygon, 0.01)
+	if result.Distance < 0.99 {
+		t.Errorf("Received %v, expected a label in the overlap of the holes", result)
+	}
+}
+
+func TestCollapsedPolygons(t *testing.T) {
+	for _, polygon := range []Polygon{
+		{Ring{Coord{3, 4}, Coord{3, 4}, Coord{3, 4}, Coord{3, 4}}},
+		{Ring{Coord{3, 4}}},
+		{Ring{Coord{3, 4}, Coord{3, 4}}, Ring{Coord{3, 4}, Coord{3, 4}, Coord{3, 4}}},
+	} {
+		result := PolylabelVerbose(polygon, 1.0, WithTangentPoints(true))
+		if !reflect.DeepEqual(result, Result{X: 3, Y: 4}) {
+			t.Errorf("Received %v for %v, expected the single vertex", result, polygon)
+		}
+		point, distance := InitialEstimate(polygon)
+		AssertEqual(t, point, Coord{3, 4})
+		AssertEqual(t, distance, 0.0)
+		result = PolylabelRect(polygon, 1.0, 2.0)
+		AssertEqual(t, result.X, 3.0)
+		AssertEqual(t, result.Y, 4.0)
+		AssertEqual(t, result.Distance, 0.0)
+	}
+
+	// a polygon too small for its size to be halved
+	tiny := math.SmallestNonzeroFloat64
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{tiny, 0}, Coord{tiny, tiny}, Coord{0, tiny}, Coord{0, 0}}}
+	result := PolylabelVerbose(polygon, 0)
+	if math.IsNaN(result.X) || math.IsNaN(result.Y) || math.IsNaN(result.Distance) || math.IsNaN(result.Score) {
+		t.Errorf("Received %v, expected no NaN values", result)
+	}
+}
+
+func BenchmarkPolylabel(b *testing.B) {
+	cases := []struct {
+		name    string
+		polygon Polygon
+	}{
+		{"water1", loadData("test_data/water1.json")},
+		{"water2", loadData("test_data/water2.json")},
+		{"synthetic100", SyntheticPolygon(100)},
+		{"synthetic10000", SyntheticPolygon(10000)},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Polylabel(c.polygon, 1.0)
+			}
+		})
+	}
+}
+
+func TestSyntheticPolygon(t *testing.T) {
+	polygon := SyntheticPolygon(64)
+	AssertEqual(t, len(polygon), 2)
+	AssertEqual(t, len(polygon[0]), 65)
+	AssertEqual(t, polygon[0][0], polygon[0][64])
+	AssertEqual(t, len(SyntheticPolygon(1)[0]), 4)
+
+	result := PolylabelVerbose(polygon, 0.1)
+	if result.Distance <= 0 {
+		t.Errorf("Received %v, expected a point inside the polygon", result)
+	}
+}
+
+func TestWithRingsAsSeparate(t *testing.T) {
+	// a small square followed by a larger one that lies outside it
+	polygon := Polygon{
+		Ring{Coord{0, 0}, Coord{2, 0}, Coord{2, 2}, Coord{0, 2}, Coord{0, 0}},
+		Ring{Coord{10, 0}, Coord{16, 0}, Coord{16, 6}, Coord{10, 6}, Coord{10, 0}},
+	}
+
+	// by default the second ring is a hole outside the exterior and ignored
+	result := PolylabelVerbose(polygon, 0.01)
+	AssertEqual(t, result.X, 1.0)
+	AssertEqual(t, result.Y, 1.0)
+
+	result = PolylabelVerbose(polygon, 0.01, WithRingsAsSeparate(true))
+	if math.Abs(result.X-13) > 0.1 || math.Abs(result.Y-3) > 0.1 || result.Distance < 2.99 {
+		t.Errorf("Received %v, expected the center of the larger square", result)
+	}
+}
+
+func TestCentroidOfHugeCoordinates(t *testing.T) {
+	// a million vertex circle whose coordinates overflow when multiplied
+	const n = 1000000
+	cx, cy, r := 1e200, -1e200, 1e195
+	ring := make(Ring, n+1)
+	for i := range ring {
+		angle := 2 * math.Pi * float64(i%n) / n
+		ring[i] = Coord{cx + r*math.Cos(angle), cy + r*math.Sin(angle)}
+	}
+	x, y := getCentroid(Polygon{ring})
+	if !(math.Abs(x-cx) <= r*1e-6 && math.Abs(y-cy) <= r*1e-6) {
+		t.Errorf("Received %v, %v, expected %v, %v", x, y, cx, cy)
+	}
+}
+
+func TestWithRefinement(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	for _, precision := range []float64{1.0, 50.0} {
+		plain := PolylabelVerbose(polygon, precision)
+		refined := PolylabelVerbose(polygon, precision, WithRefinement(true))
+		if refined.Distance < plain.Distance {
+			t.Errorf("Received distance %v, expected at least %v", refined.Distance, plain.Distance)
+		}
+		for _, d := range refined.Corners {
+			if d > refined.Distance {
+				t.Errorf("Received corner distance %v greater than the refined %v", d, refined.Distance)
+			}
+		}
+		AssertEqual(t, plain.Corners, [4]float64{})
+	}
+
+	// a coarse search of a triangle is improved by the gradient step
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{0, 3}, Coord{0, 0}}}
+	plain := PolylabelVerbose(polygon, 0.5)
+	refined := PolylabelVerbose(polygon, 0.5, WithRefinement(true))
+	if refined.Distance <= plain.Distance {
+		t.Errorf("Received distance %v, expected more than %v", refined.Distance, plain.Distance)
+	}
+}
+
+// a regular polygon with n sides inscribed in a circle of radius r
+func regularPolygon(n int, r, cx, cy, rotation float64) Polygon {
+	ring := make(Ring, n+1)
+	for i := 0; i < n; i++ {
+		angle := rotation + 2*math.Pi*float64(i)/float64(n)
+		ring[i] = Coord{cx + r*math.Cos(angle), cy + r*math.Sin(angle)}
+	}
+	ring[n] = ring[0]
+	return Polygon{ring}
+}
+
+func TestSymmetricPolygonsLabelledAtCenter(t *testing.T) {
+	cases := []struct {
+		name    string
+		polygon Polygon
+	}{
+		{"hexagon", regularPolygon(6, 10, 3, 4, 0)},
+		{"rotated hexagon", regularPolygon(6, 10, 3, 4, math.Pi/6)},
+		{"circle", regularPolygon(64, 10, 3, 4, 0)},
+		{"odd circle", regularPolygon(63, 10, 3, 4, 0)},
+	}
+	for _, c := range cases {
+		for _, bboxCell := range []bool{true, false} {
+			for _, precision := range []float64{0.001, 0.1, 1.0} {
+				result := PolylabelVerbose(c.polygon, precision, WithBBoxCell(bboxCell))
+				if math.Abs(result.X-3) > 1e-9 || math.Abs(result.Y-4) > 1e-9 {
+					t.Errorf("%s: Received %v, %v, expected 3, 4", c.name, result.X, result.Y)
+				}
+			}
+		}
+	}
+}
+
+func TestTiedCellsPreferBoundingBoxCenter(t *testing.T) {
+	s := &cellSearch{best: newCell(1, 0, 0, 5), center: Coord{0, 0}}
+	AssertEqual(t, s.improves(newCell(2, 0, 0, 5), s.best), false)
+	AssertEqual(t, s.improves(newCell(0, 0.5, 0, 5), s.best), true)
+	AssertEqual(t, s.improves(newCell(5, 5, 0, 6), s.best), true)
+	AssertEqual(t, s.improves(newCell(0, 0, 0, 4), s.best), false)
+}
+
+func TestPrecisionLargerThanPolygon(t *testing.T) {
+	// the better of the centroid and bounding box center is kept, even when
+	// neither is inside the polygon
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 1}, Coord{1, 1}, Coord{1, 10}, Coord{0, 10}, Coord{0, 0}}}
+	for _, precision := range []float64{10, 1000} {
+		search := NewSearch(polygon, precision)
+		AssertEqual(t, search.Done(), true)
+		result := PolylabelVerbose(polygon, precision)
+		cx, cy := getCentroid(polygon)
+		if d := pointToPolygonDistance(cx, cy, polygon); result.Distance < d {
+			t.Errorf("Received %v, expected at least the centroid distance %v", result.Distance, d)
+		}
+	}
+
+	// a long thin rectangle is labelled at its center without any search
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{100, 0}, Coord{100, 2}, Coord{0, 2}, Coord{0, 0}}}
+	search := NewSearch(polygon, 5)
+	AssertEqual(t, search.Done(), true)
+	result := search.Result()
+	AssertEqual(t, result.X, 50.0)
+	AssertEqual(t, result.Y, 1.0)
+}
+
+func TestWithClampInside(t *testing.T) {
+	// a coarse precision labels an L shape at its centroid or bounding box
+	// center, both of which are outside it
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 1}, Coord{1, 1}, Coord{1, 10}, Coord{0, 10}, Coord{0, 0}}}
+	if result := PolylabelVerbose(polygon, 10); result.Distance > 0 {
+		t.Fatalf("Received %v, expected a label outside the polygon", result)
+	}
+	result := PolylabelVerbose(polygon, 10, WithClampInside(true))
+	if result.Distance <= 0 || pointToPolygonDistance(result.X, result.Y, polygon) != result.Distance {
+		t.Errorf("Received %v, expected a label inside the polygon", result)
+	}
+
+	// a label on the outline is nudged inside, and one inside is left alone
+	cellAt := newOptions(nil).cellFunc(polygon)
+	for _, p := range []Coord{{5, 0}, {10, 0.5}, {0, 0}, {1, 1}} {
+		c := clampInside(cellAt(p[0], p[1], 0), cellAt)
+		if c.d <= 0 {
+			t.Errorf("Received %v, expected a point inside the polygon", c)
+		}
+	}
+	inside := cellAt(0.5, 5, 0)
+	AssertEqual(t, clampInside(inside, cellAt), inside)
+
+	// other labels are unchanged
+	polygon = loadData("test_data/water1.json")
+	if a, b := PolylabelVerbose(polygon, 1.0), PolylabelVerbose(polygon, 1.0, WithClampInside(true)); !reflect.DeepEqual(a, b) {
+		t.Errorf("Received %v, expected %v", b, a)
+	}
+}
+
+func TestAnnulus(t *testing.T) {
+	// a band between circles of radius 4 and 10, with the hole wound
+	// clockwise as the winding rule requires
+	outer := regularPolygon(64, 10, 3, 4, 0)[0]
+	hole := reverseRing(regularPolygon(64, 4, 3, 4, 0)[0])
+	polygon := Polygon{outer, hole}
+
+	// the rings are inscribed in their circles, which changes the band width
+	// by less than 0.02
+	band := 6.0
+	for _, rule := range []ContainmentRule{EvenOdd, Winding} {
+		result := PolylabelVerbose(polygon, 0.01, WithContainmentRule(rule))
+		if math.Abs(result.Distance-band/2) > 0.02 {
+			t.Errorf("Received distance %v, expected half the band width %v", result.Distance, band/2)
+		}
+		if r := math.Hypot(result.X-3, result.Y-4); math.Abs(r-(4+band/2)) > 0.02 {
+			t.Errorf("Received %v, %v at radius %v, expected the middle of the band", result.X, result.Y, r)
+		}
+	}
+}
+
+func TestWithOutputTransform(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+	calls := 0
+	shift := func(x, y float64) (float64, float64) {
+		calls++
+		return x + 0.25, y * 2
+	}
+	result := PolylabelVerbose(polygon, 1.0, WithOutputTransform(shift), WithOutputRounding(0))
+	AssertEqual(t, calls, 1)
+	AssertEqual(t, result.X, math.Round(expected.X+0.25))
+	AssertEqual(t, result.Y, math.Round(expected.Y*2))
+	AssertEqual(t, result.Distance, expected.Distance)
+}
+
+func TestPolylabelRing(t *testing.T) {
+	small := Ring{Coord{0, 0}, Coord{2, 0}, Coord{2, 2}, Coord{0, 2}, Coord{0, 0}}
+	large := Ring{Coord{10, 0}, Coord{16, 0}, Coord{16, 6}, Coord{10, 6}, Coord{10, 0}}
+	polygon := Polygon{small, large}
+
+	for i, expected := range []Coord{{1, 1}, {13, 3}} {
+		result, err := PolylabelRing(polygon, i, 0.01)
+		if err != nil {
+			t.Fatal(err)
+		}
+		AssertEqual(t, Coord{result.X, result.Y}, expected)
+	}
+
+	// weights follow the chosen ring
+	weights := [][]float64{{1, 1, 1, 1}, {0.5}}
+	result, err := PolylabelRing(polygon, 1, 0.01, WithEdgeWeights(weights))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PolylabelVerbose(Polygon{large}, 0.01, WithEdgeWeights(weights[1:]))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	for _, i := range []int{-1, 2} {
+		if _, err := PolylabelRing(polygon, i, 0.01); err == nil {
+			t.Errorf("Expected an error for ring %d", i)
+		}
+	}
+}
+
+func TestWithSubpixelSnap(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{7.4, 0}, Coord{7.4, 3}, Coord{0, 3}, Coord{0, 0}}}
+	result := PolylabelVerbose(polygon, 0.01, WithSubpixelSnap(0.5))
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{3.5, 1.5})
+	AssertEqual(t, result.Distance, 1.5)
+
+	result = PolylabelVerbose(polygon, 0.01, WithSubpixelSnap(0.25))
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{3.25, 1.25})
+
+	// negative coordinates snap to the nearest point too
+	x, y := newOptions([]Option{WithSubpixelSnap(0.5)}).output(-6.3, -8.5)
+	AssertEqual(t, Coord{x, y}, Coord{-6.5, -8.5})
+}
+
+func TestWithGridQuantize(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{7.4, 0}, Coord{7.4, 3}, Coord{0, 3}, Coord{0, 0}}}
+	result := PolylabelVerbose(polygon, 0.01, WithGridQuantize(2, QuantizeRound))
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{4, 2})
+	AssertEqual(t, result.Distance, 1.5)
+	result = PolylabelVerbose(polygon, 0.01, WithGridQuantize(2, QuantizeFloor))
+	AssertEqual(t, Coord{result.X, result.Y}, Coord{2, 0})
+
+	// neighbouring labels fall on the same point
+	for _, p := range []Coord{{10.1, 20.9}, {11.9, 21.2}, {10, 20}} {
+		x, y := newOptions([]Option{WithGridQuantize(2, QuantizeFloor)}).output(p[0], p[1])
+		AssertEqual(t, Coord{x, y}, Coord{10, 20})
+	}
+	x, y := newOptions([]Option{WithGridQuantize(0.5, QuantizeRound)}).output(-6.3, -8.6)
+	AssertEqual(t, Coord{x, y}, Coord{-6.5, -8.5})
+
+	// it comes before the subpixel snap, and a size of 0 disables it
+	x, y = newOptions([]Option{WithGridQuantize(2, QuantizeFloor), WithSubpixelSnap(0.5)}).output(3, 3)
+	AssertEqual(t, Coord{x, y}, Coord{2.5, 2.5})
+	x, y = newOptions([]Option{WithGridQuantize(0, QuantizeFloor)}).output(3.3, 3.3)
+	AssertEqual(t, Coord{x, y}, Coord{3.3, 3.3})
+}
+
+func TestThreePointRings(t *testing.T) {
+	// three distinct coordinates are closed into a triangle
+	open := Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 6}}}
+	closed := Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 6}, Coord{0, 0}}}
+	expected := PolylabelVerbose(closed, 0.01)
+	result := PolylabelVerbose(open, 0.01)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	if result.Distance <= 1.7 {
+		t.Errorf("Received %v, expected the incircle of the triangle", result.Distance)
+	}
+	AssertEqual(t, len(open[0]), 3)
+
+	// a ring that goes out and back is a line with no inside
+	line := Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 0}}}
+	result = PolylabelVerbose(line, 0.01)
+	if result.Distance > 0 {
+		t.Errorf("Received %v, expected no point inside a line", result)
+	}
+}
+
+func TestNegativeAndOriginCrossingPolygons(t *testing.T) {
+	// squares entirely in negative space and straddling the origin
+	for _, c := range []struct {
+		polygon  Polygon
+		expected Coord
+	}{
+		{Polygon{Ring{Coord{-10, -10}, Coord{-6, -10}, Coord{-6, -6}, Coord{-10, -6}, Coord{-10, -10}}}, Coord{-8, -8}},
+		{Polygon{Ring{Coord{-2, -2}, Coord{2, -2}, Coord{2, 2}, Coord{-2, 2}, Coord{-2, -2}}}, Coord{0, 0}},
+		{Polygon{Ring{Coord{-3, 1}, Coord{1, 1}, Coord{1, 5}, Coord{-3, 5}, Coord{-3, 1}}}, Coord{-1, 3}},
+	} {
+		result := PolylabelVerbose(c.polygon, 0.01)
+		AssertEqual(t, Coord{result.X, result.Y}, c.expected)
+		AssertEqual(t, result.Distance, 2.0)
+		AssertEqual(t, result.Score, 1.0)
+	}
+
+	// water1 moved into each quadrant and around the origin, and mirrored,
+	// is labelled as well as the original
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+	for _, transform := range []func(c Coord) Coord{
+		func(c Coord) Coord { return Coord{c[0] - 8000, c[1] - 4000} },
+		func(c Coord) Coord { return Coord{c[0] - 3900, c[1] - 2100} },
+		func(c Coord) Coord { return Coord{-c[0], c[1]} },
+		func(c Coord) Coord { return Coord{-c[0], -c[1]} },
+	} {
+		moved := make(Polygon, len(polygon))
+		for i, ring := range polygon {
+			for _, coord := range ring {
+				moved[i] = append(moved[i], transform(coord))
+			}
+		}
+		result := PolylabelVerbose(moved, 1.0)
+		if math.Abs(result.Distance-expected.Distance) > 1.0 {
+			t.Errorf("Received distance %v, expected within 1 of %v", result.Distance, expected.Distance)
+		}
+		if d := pointToPolygonDistance(result.X, result.Y, moved); d <= 0 || math.Abs(d-result.Distance) > 1e-9 {
+			t.Errorf("Received %v, %v at distance %v, expected a point inside at distance %v", result.X, result.Y, d, result.Distance)
+		}
+	}
+}
+
+func TestWithPrecisionXY(t *testing.T) {
+	// a shape a thousand units long and ten high, with X in units a hundred
+	// times coarser than Y, is square once stretched
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{400, 0}, Coord{1000, 10}, Coord{0, 10}, Coord{0, 0}}}
+	stretched := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	expected := PolylabelVerbose(stretched, 0.01, WithTangentPoints(true))
+	result := PolylabelVerbose(polygon, 1e9, WithPrecisionXY(1, 0.01), WithTangentPoints(true))
+	if math.Abs(result.X-expected.X*100) > 1e-9 || result.Y != expected.Y || result.Distance != expected.Distance {
+		t.Errorf("Received %v, expected %v with X scaled by 100", result, expected)
+	}
+	AssertEqual(t, len(result.Tangents), len(expected.Tangents))
+	for i, p := range result.Tangents {
+		if math.Abs(p[0]-expected.Tangents[i][0]*100) > 1e-9 || p[1] != expected.Tangents[i][1] {
+			t.Errorf("Received tangent %v, expected %v with X scaled by 100", p, expected.Tangents[i])
+		}
+	}
+
+	// the validator sees the original coordinates
+	var seen []Coord
+	PolylabelVerbose(polygon, 1, WithPrecisionXY(1, 0.01), WithResultValidator(func(p Coord, distance float64) bool {
+		seen = append(seen, p)
+		return true
+	}))
+	for _, p := range seen {
+		if p[0] < 0 || p[0] > 1000 {
+			t.Errorf("Received %v, expected a point of the original polygon", p)
+		}
+	}
+
+	// equal precisions only replace the precision argument
+	expected = PolylabelVerbose(polygon, 0.5)
+	if result := PolylabelVerbose(polygon, 100, WithPrecisionXY(0.5, 0.5)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestPolylabelAtLeast(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+	result, err := PolylabelAtLeast(polygon, 1.0, 100)
+	if err != nil || !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, %v, expected %v", result, err, expected)
+	}
+
+	// the label is still returned when it is too close to the outline
+	result, err = PolylabelAtLeast(polygon, 1.0, 1000)
+	AssertEqual(t, err, ErrNoLabel)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	for _, polygon := range []Polygon{
+		{},
+		{Ring{}},
+		{Ring{Coord{3, 4}}},
+		{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 0}}},
+	} {
+		if _, err := PolylabelAtLeast(polygon, 1.0, 0); err != ErrNoLabel {
+			t.Errorf("Received %v for %v, expected ErrNoLabel", err, polygon)
+		}
+	}
+
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	_, err = PolylabelAtLeast(square, 0.1, 0, WithResultValidator(func(p Coord, distance float64) bool {
+		return false
+	}))
+	AssertEqual(t, err, ErrNoLabel)
+}
+
+func TestWithMinCorridorWidth(t *testing.T) {
+	// a dumbbell of two 4 by 4 bodies joined by a neck 2 wide, whose
+	// centroid, the first candidate, is in the middle of the neck
+	dumbbell := Polygon{Ring{
+		Coord{0, 0}, Coord{4, 0}, Coord{4, 1}, Coord{14, 1}, Coord{14, 0}, Coord{18, 0},
+		Coord{18, 4}, Coord{14, 4}, Coord{14, 3}, Coord{4, 3}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0},
+	}}
+	for _, precision := range []float64{1, 0.1} {
+		result := PolylabelVerbose(dumbbell, precision, WithMinCorridorWidth(3))
+		inBody := result.X < 4 || result.X > 14
+		if result.Rejected || !inBody || result.Distance < 1.5 {
+			t.Errorf("Received %v at precision %v, expected a label in a body", result, precision)
+		}
+	}
+
+	// it combines with the result validator
+	result := PolylabelVerbose(dumbbell, 0.1, WithMinCorridorWidth(3), WithResultValidator(func(p Coord, distance float64) bool {
+		return p[0] > 9
+	}))
+	if result.Rejected || result.X < 14 || result.Distance < 1.5 {
+		t.Errorf("Received %v, expected a label in the right body", result)
+	}
+
+	// nowhere is 5 wide
+	if _, err := PolylabelAtLeast(dumbbell, 0.1, 0, WithMinCorridorWidth(5)); err != ErrNoLabel {
+		t.Errorf("Received %v, expected ErrNoLabel", err)
+	}
+}
+
+func TestWithViewport(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{100, 0}, Coord{100, 100}, Coord{0, 100}, Coord{0, 0}}}
+
+	// a viewport over the top right corner of the square pushes the label
+	// into the middle of the visible part
+	result := PolylabelVerbose(polygon, 0.01, WithViewport(60, 60, 200, 200))
+	if math.Abs(result.X-80) > 0.01 || math.Abs(result.Y-80) > 0.01 || math.Abs(result.Distance-20) > 0.01 {
+		t.Errorf("Received %v, expected near (80, 80) at distance 20", result)
+	}
+
+	// a viewport around the whole polygon changes nothing
+	expected := PolylabelVerbose(polygon, 0.01)
+	result = PolylabelVerbose(polygon, 0.01, WithViewport(-1000, -1000, 1000, 1000))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	o := newOptions([]Option{WithViewport(0, 0, 10, 10)})
+	AssertEqual(t, o.viewportDistance(2, 5), 2.0)
+	AssertEqual(t, o.viewportDistance(13, 14), -5.0)
+	AssertEqual(t, o.viewportDistance(5, -1), -1.0)
+}
+
+func TestClone(t *testing.T) {
+	polygon := Polygon{
+		Ring{Coord{0, 0}, Coord{0, 4}, Coord{4, 4}, Coord{4, 0}},
+		Ring{},
+		nil,
+	}
+	cloned := polygon.Clone()
+	if !reflect.DeepEqual(cloned, polygon) {
+		t.Fatalf("Received %v, expected %v", cloned, polygon)
+	}
+	cloned[0][1] = Coord{9, 9}
+	AssertEqual(t, polygon[0][1], Coord{0, 4})
+	if Polygon(nil).Clone() != nil {
+		t.Errorf("Expected a nil clone of a nil polygon")
+	}
+
+	// the helpers leave their input alone, here an unclosed clockwise ring
+	// with a spike and a repeated point
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{0, 4}, Coord{0, 4}, Coord{4, 4}, Coord{6, 4}, Coord{4, 4}, Coord{4, 0}}}
+	original := polygon.Clone()
+	EnsureCCW(polygon)
+	NormalizeRFC7946(polygon)
+	RemoveSpikes(polygon)
+	Densify(polygon, 1)
+	RepairRings([]Polygon{polygon})
+	PolylabelVerbose(polygon, 0.1, WithRFC7946(true), WithSpikePruning(true), WithMaxVertices(4))
+	if !reflect.DeepEqual(polygon, original) {
+		t.Errorf("Received %v, expected %v", polygon, original)
+	}
+}
+
+func TestCentroidInside(t *testing.T) {
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	AssertEqual(t, CentroidInside(square), true)
+	// a hole over the centroid
+	AssertEqual(t, CentroidInside(append(square, Ring{Coord{1, 1}, Coord{1, 3}, Coord{3, 3}, Coord{3, 1}, Coord{1, 1}})), false)
+	AssertEqual(t, CentroidInside(Polygon{}), false)
+	AssertEqual(t, CentroidInside(Polygon{Ring{}}), false)
+	AssertEqual(t, CentroidInside(Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 0}}}), false)
+
+	// a U shape has its centroid between the arms, where the seed cell is
+	// outside; any cell inside replaces it as the best
+	u := Polygon{Ring{
+		Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{8, 10}, Coord{8, 2},
+		Coord{2, 2}, Coord{2, 10}, Coord{0, 10}, Coord{0, 0},
+	}}
+	AssertEqual(t, CentroidInside(u), false)
+	cx, cy := getCentroid(u)
+	if d := pointToPolygonDistance(cx, cy, u); d >= 0 {
+		t.Fatalf("Received %v, expected the centroid outside", d)
+	}
+	for _, precision := range []float64{1, 0.1, 0.01} {
+		result := PolylabelVerbose(u, precision)
+		if result.Distance <= 0 || result.X == cx && result.Y == cy {
+			t.Errorf("Received %v at precision %v, expected a label inside", result, precision)
+		}
+	}
+}
+
+func TestHugePrecision(t *testing.T) {
+	// no cell is split, leaving the better of the centroid and the bounding
+	// box center
+	polygon := loadData("test_data/water1.json")
+	cx, cy := getCentroid(polygon)
+	minX, minY, maxX, maxY := boundingBox(polygon)
+	centroid := pointToPolygonDistance(cx, cy, polygon)
+	center := pointToPolygonDistance((minX+maxX)/2, (minY+maxY)/2, polygon)
+	expected := Coord{cx, cy}
+	if center >= centroid {
+		expected = Coord{(minX + maxX) / 2, (minY + maxY) / 2}
+	}
+	for _, precision := range []float64{math.Min(maxX-minX, maxY-minY), 1e300, math.MaxFloat64, math.Inf(1)} {
+		result, stats := PolylabelStats(polygon, precision)
+		AssertEqual(t, Coord{result.X, result.Y}, expected)
+		AssertEqual(t, result.Distance, math.Max(centroid, center))
+		AssertEqual(t, stats.Subdivisions, 0)
+	}
+}
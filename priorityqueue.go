@@ -1,50 +1,116 @@
-package main
+package polylabel
 
-import "container/heap"
+import (
+	"math"
+	"sort"
+)
 
-// An Item is something we manage in a priority queue.
-type Item struct {
-	value    *Cell // The value of the item; arbitrary.
-	priority float64         // The priority of the item in the queue.
-	// The index is needed by update and is maintained by the heap.Interface methods.
-	index int // The index of the item in the heap.
+// A cellQueue is a max-heap of cells ordered by the greatest distance each
+// could contain, or by the distance at their centers for WithGreedySearch:
+// every cell is above its children in that order. The cells are held by value
+// so the queue needs no pointers, and it is specialized to cells rather than
+// using container/heap so that comparisons and swaps can be inlined. Cells
+// move through the heap in the same way as with container/heap.
+type cellQueue []cell
+
+// the initial queue capacity is capped so that a tiny precision does not
+// allocate a huge queue up front
+const maxQueueEstimate = 1 << 12
+
+// estimate how many cells a search will need to queue: pruning keeps the queue
+// to a few cells for each level of subdivision below each initial cell
+func estimateQueueSize(width float64, height float64, precision float64) int {
+	cellSize := math.Min(width, height)
+	initialCells := math.Ceil(width/cellSize) * math.Ceil(height/cellSize)
+	levels := math.Max(1, math.Log2(cellSize/precision))
+	estimate := 8 * initialCells * levels
+	if !(estimate < maxQueueEstimate) {
+		return maxQueueEstimate
+	}
+	return int(estimate)
 }
 
-// A PriorityQueue implements heap.Interface and holds Items.
-type PriorityQueue []*Item
+// push adds a cell to the queue.
+func (q *cellQueue) push(c cell, byDistance bool) {
+	*q = append(*q, c)
+	q.up(len(*q)-1, byDistance)
+}
 
-func (pq PriorityQueue) Len() int { return len(pq) }
+// pop removes the cell with the greatest max, or distance if byDistance is
+// set, from the queue.
+func (q *cellQueue) pop(byDistance bool) cell {
+	old := *q
+	n := len(old) - 1
+	old[0], old[n] = old[n], old[0]
+	c := old[n]
+	*q = old[:n]
+	q.down(0, byDistance)
+	return c
+}
 
-func (pq PriorityQueue) Less(i, j int) bool {
-	// We want Pop to give us the highest, not lowest, priority so we use greater than here.
-	return pq[i].priority > pq[j].priority
+// discard the cells with the smallest maxes, or distances if byDistance is
+// set, until the queue holds at most three quarters of n, so that a queue
+// growing past n is not cut back on every step, returning how many cells were
+// discarded
+func (q *cellQueue) truncate(n int, byDistance bool) int {
+	old := *q
+	if len(old) <= n {
+		return 0
+	}
+	keep := n - n/4
+	if keep < 1 {
+		keep = 1
+	}
+	// cells sorted by decreasing priority are already in heap order
+	sort.Slice(old, func(i, j int) bool { return old.above(i, j, byDistance) })
+	*q = old[:keep]
+	return len(old) - keep
 }
 
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
+// restore the heap order of a queue whose cells are in any order
+func (q cellQueue) init(byDistance bool) {
+	for i := len(q)/2 - 1; i >= 0; i-- {
+		q.down(i, byDistance)
+	}
 }
 
-func (pq *PriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*Item)
-	item.index = n
-	*pq = append(*pq, item)
+// report whether the cell at i belongs above the cell at j
+func (q cellQueue) above(i int, j int, byDistance bool) bool {
+	if byDistance {
+		return q[i].d > q[j].d
+	}
+	return q[i].max > q[j].max
 }
 
-func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	item.index = -1 // for safety
-	*pq = old[0 : n-1]
-	return item
+// move the cell at i towards the root until its parent is above it, by max
+// or, if byDistance is set, by distance
+func (q cellQueue) up(i int, byDistance bool) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.above(i, parent, byDistance) {
+			break
+		}
+		q[i], q[parent] = q[parent], q[i]
+		i = parent
+	}
 }
 
-// update modifies the priority and value of an Item in the queue.
-func (pq *PriorityQueue) update(item *Item, value *Cell, priority float64) {
-	item.value = value
-	item.priority = priority
-	heap.Fix(pq, item.index)
+// move the cell at i away from the root until it is above its children, by
+// max or, if byDistance is set, by distance
+func (q cellQueue) down(i int, byDistance bool) {
+	n := len(q)
+	for {
+		child := 2*i + 1
+		if child >= n || child < 0 { // child < 0 after int overflow
+			break
+		}
+		if right := child + 1; right < n && q.above(right, child, byDistance) {
+			child = right
+		}
+		if !q.above(child, i, byDistance) {
+			break
+		}
+		q[i], q[child] = q[child], q[i]
+		i = child
+	}
 }
@@ -0,0 +1,112 @@
+package polylabel
+
+import (
+	"container/heap"
+	"reflect"
+	"testing"
+)
+
+// a queue using container/heap to check that cellQueue behaves identically
+type referenceQueue []cell
+
+func (q referenceQueue) Len() int            { return len(q) }
+func (q referenceQueue) Less(i, j int) bool  { return q[i].max > q[j].max }
+func (q referenceQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *referenceQueue) Push(x interface{}) { *q = append(*q, x.(cell)) }
+func (q *referenceQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	*q = old[0 : n-1]
+	return c
+}
+
+func TestCellQueue(t *testing.T) {
+	maxes := []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+
+	// push and pop order cells in the same way as heap.Push and heap.Pop
+	q := make(cellQueue, 0)
+	reference := make(referenceQueue, 0)
+	for i, max := range maxes {
+		c := cell{x: float64(i), max: max}
+		q.push(c, false)
+		heap.Push(&reference, c)
+		if !reflect.DeepEqual([]cell(q), []cell(reference)) {
+			t.Fatalf("Received queue %v, expected %v", q, reference)
+		}
+	}
+
+	previous := 10.0
+	for len(q) > 0 {
+		c := q.pop(false)
+		AssertEqual(t, c, heap.Pop(&reference).(cell))
+		if !reflect.DeepEqual([]cell(q), []cell(reference)) {
+			t.Fatalf("Received queue %v, expected %v", q, reference)
+		}
+		if c.max > previous {
+			t.Errorf("Popped max %v after %v", c.max, previous)
+		}
+		previous = c.max
+	}
+}
+
+func TestCellQueueInit(t *testing.T) {
+	q := make(cellQueue, 0)
+	reference := make(referenceQueue, 0)
+	for i, max := range []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5} {
+		q = append(q, cell{x: float64(i), max: max})
+		reference = append(reference, cell{x: float64(i), max: max})
+	}
+	q.init(false)
+	heap.Init(&reference)
+	if !reflect.DeepEqual([]cell(q), []cell(reference)) {
+		t.Errorf("Received queue %v, expected %v", q, reference)
+	}
+}
+
+func TestCellQueueByDistance(t *testing.T) {
+	q := make(cellQueue, 0)
+	for i, d := range []float64{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5} {
+		// the maxes run the other way, to show they are ignored
+		q.push(cell{x: float64(i), d: d, max: 10 - d}, true)
+	}
+	previous := 10.0
+	for len(q) > 0 {
+		c := q.pop(true)
+		if c.d > previous {
+			t.Errorf("Popped distance %v after %v", c.d, previous)
+		}
+		previous = c.d
+	}
+}
+
+func TestEstimateQueueSize(t *testing.T) {
+	AssertEqual(t, estimateQueueSize(10, 20, 10), 16)
+	AssertEqual(t, estimateQueueSize(10, 20, 1.25), 48)
+	AssertEqual(t, estimateQueueSize(10, 20, 0), maxQueueEstimate)
+	AssertEqual(t, estimateQueueSize(1, 1e9, 1e-300), maxQueueEstimate)
+}
+
+func BenchmarkCellQueue(b *testing.B) {
+	q := make(cellQueue, 0, 1024)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 1024; j++ {
+			q.push(cell{max: float64((j * 7919) % 1024)}, false)
+		}
+		for len(q) > 0 {
+			q.pop(false)
+		}
+	}
+}
+
+func BenchmarkContainerHeapQueue(b *testing.B) {
+	q := make(referenceQueue, 0, 1024)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 1024; j++ {
+			heap.Push(&q, cell{max: float64((j * 7919) % 1024)})
+		}
+		for q.Len() > 0 {
+			heap.Pop(&q)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package polylabel
+
+import "math"
+
+// Rect is an axis-aligned rectangle.
+type Rect struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+// Contains reports whether the point x, y lies in r or on its edges.
+func (r Rect) Contains(x float64, y float64) bool {
+	return x >= r.MinX && x <= r.MaxX && y >= r.MinY && y <= r.MaxY
+}
+
+// Intersects reports whether r and other share any point, including where
+// they only touch.
+func (r Rect) Intersects(other Rect) bool {
+	return r.MinX <= other.MaxX && other.MinX <= r.MaxX && r.MinY <= other.MaxY && other.MinY <= r.MaxY
+}
+
+// ToPolygon returns r as a Polygon with a single closed, counterclockwise
+// ring.
+func (r Rect) ToPolygon() Polygon {
+	return Polygon{Ring{
+		Coord{r.MinX, r.MinY}, Coord{r.MaxX, r.MinY}, Coord{r.MaxX, r.MaxY}, Coord{r.MinX, r.MaxY}, Coord{r.MinX, r.MinY},
+	}}
+}
+
+// Bounds returns the bounding box of the exterior ring of polygon, which
+// contains any holes, or the zero Rect if polygon is empty.
+func (polygon Polygon) Bounds() Rect {
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return Rect{}
+	}
+	minX, minY, maxX, maxY := boundingBox(polygon)
+	return Rect{minX, minY, maxX, maxY}
+}
+
+// PolylabelRect finds the center of the largest axis-aligned rectangle with
+// the given aspect ratio (width divided by height) that fits inside polygon.
+// This suits text labels better than the largest inscribed circle, since text
+// is usually wider than it is tall. aspect must be positive.
+//
+// The Distance of the result is half the height of the rectangle, and
+// precision applies to it. This function is experimental.
+func PolylabelRect(polygon Polygon, precision float64, aspect float64) Result {
+	o := newOptions(nil)
+	polygon = closeRings(polygon)
+	minX, minY, maxX, maxY := boundingBox(polygon)
+
+	width := maxX - minX
+	height := maxY - minY
+	if math.Min(width, height) == 0 {
+		return Result{X: midpoint(minX, maxX), Y: midpoint(minY, maxY)}
+	}
+
+	// moving the center by h along both axes changes the half height of the
+	// rectangle by at most h, or h/aspect if that is larger
+	bound := math.Max(1, 1/aspect)
+	cellAt := func(x, y, h float64) cell {
+		d := pointToPolygonRectDistance(x, y, polygon, aspect)
+		return cell{x, y, h, d, d + h*bound}
+	}
+
+	bestCell := cellAt(minX+width/2, minY+height/2, 0)
+	bestCell = searchCells(minX, minY, maxX, maxY, precision, bestCell, cellAt, o)
+
+	// the half height of the largest rectangle that fits the bounding box
+	maxHalfHeight := math.Min(height, width/aspect) / 2
+	return Result{
+		X:        bestCell.x,
+		Y:        bestCell.y,
+		Distance: bestCell.d,
+		Score:    labelScore(bestCell.d, maxHalfHeight*2),
+	}
+}
+
+// signed half height of the largest rectangle with the given aspect ratio
+// centered on a point that does not cross the polygon outline (negative if
+// point is outside)
+func pointToPolygonRectDistance(x float64, y float64, polygon Polygon, aspect float64) float64 {
+	inside := false
+	minDist := math.Inf(1)
+
+	for _, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			a := ring[n]
+			b := ring[n+1]
+			if rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			minDist = math.Min(minDist, segmentRectDistance(x, y, a, b, aspect))
+		}
+	}
+
+	factor := 1.0
+	if !inside {
+		factor = -1.0
+	}
+	return factor * minDist
+}
+
+// get the distance from a point to a segment in the metric
+// max(|dx| / aspect, |dy|), whose unit ball is a rectangle of that aspect
+func segmentRectDistance(px float64, py float64, a [2]float64, b [2]float64, aspect float64) float64 {
+	u := (a[0] - px) / aspect
+	v := a[1] - py
+	du := (b[0] - a[0]) / aspect
+	dv := b[1] - a[1]
+
+	dist := func(t float64) float64 {
+		return math.Max(math.Abs(u+du*t), math.Abs(v+dv*t))
+	}
+
+	// the distance along the segment is convex and piecewise linear, so the
+	// minimum is at an end point or where both components are equal in size
+	minDist := math.Min(dist(0), dist(1))
+	if du != dv {
+		if t := (v - u) / (du - dv); t > 0 && t < 1 {
+			minDist = math.Min(minDist, dist(t))
+		}
+	}
+	if du != -dv {
+		if t := -(u + v) / (du + dv); t > 0 && t < 1 {
+			minDist = math.Min(minDist, dist(t))
+		}
+	}
+	return minDist
+}
@@ -0,0 +1,83 @@
+package polylabel
+
+import "math"
+
+// WithRefinement runs a refinement step once the search has finished. The
+// distances at the four corners of the final cell are evaluated and reported
+// in Result.Corners, and the distance gradient they give is followed for one
+// step from the center of the cell. The best of these points is returned if
+// it improves on the center. The final cell of a search is precision across
+// when it comes from the initial centroid or bounding box candidates.
+func WithRefinement(enabled bool) Option {
+	return func(o *options) {
+		o.refine = enabled
+	}
+}
+
+// evaluate the corners of a cell and one gradient step from its center,
+// returning the best cell found and the distances at the corners
+func refineCell(c cell, precision float64, cellAt cellFunc) (cell, [4]float64) {
+	h := c.h
+	if h == 0 {
+		h = precision / 2
+	}
+	if h == 0 {
+		return c, [4]float64{c.d, c.d, c.d, c.d}
+	}
+
+	var corners [4]float64
+	best := c
+	for i, offset := range [4]Coord{{-h, -h}, {h, -h}, {-h, h}, {h, h}} {
+		corner := cellAt(c.x+offset[0], c.y+offset[1], 0)
+		corners[i] = corner.d
+		if corner.d > best.d {
+			best = corner
+		}
+	}
+
+	// central differences across the cell
+	gx := (corners[1] + corners[3] - corners[0] - corners[2]) / (4 * h)
+	gy := (corners[2] + corners[3] - corners[0] - corners[1]) / (4 * h)
+	if step := cellAt(c.x+gx*h, c.y+gy*h, 0); step.d > best.d {
+		best = step
+	}
+	return best, corners
+}
+
+// WithClampInside moves a label that lies on or outside the outline, which
+// can happen for thin polygons or coarse precision, into the polygon. The
+// label is moved up the distance gradient until it is strictly inside, so
+// Result.Distance is positive unless the polygon has no interior near the
+// label.
+func WithClampInside(enabled bool) Option {
+	return func(o *options) {
+		o.clampInside = enabled
+	}
+}
+
+// attempts at moving a label inside before giving up
+const maxClampSteps = 64
+
+// move a cell that is not inside the polygon up the distance gradient until it
+// is, stepping to the outline and a nudge past it, and doubling the nudge
+// whenever a step does not get inside
+func clampInside(c cell, cellAt cellFunc) cell {
+	nudge := 1e-9 * math.Max(1, math.Max(math.Abs(c.x), math.Abs(c.y)))
+	for i := 0; i < maxClampSteps && !(c.d > 0); i++ {
+		// the gradient is smooth for a fraction of the distance around the
+		// point, away from any corner of the outline
+		h := math.Max(math.Abs(c.d)/4, nudge)
+		gx := (cellAt(c.x+h, c.y, 0).d - cellAt(c.x-h, c.y, 0).d) / (2 * h)
+		gy := (cellAt(c.x, c.y+h, 0).d - cellAt(c.x, c.y-h, 0).d) / (2 * h)
+		norm := math.Hypot(gx, gy)
+		if norm > 0 && !math.IsInf(norm, 0) {
+			move := math.Max(-c.d, 0) + nudge
+			if next := cellAt(c.x+gx/norm*move, c.y+gy/norm*move, 0); next.d > c.d {
+				c = next
+				continue
+			}
+		}
+		nudge *= 2
+	}
+	return c
+}
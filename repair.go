@@ -0,0 +1,67 @@
+package polylabel
+
+// RepairReport counts the repairs made by RepairRings.
+type RepairReport struct {
+	// ClosedRings is the number of rings closed by repeating their first
+	// coordinate, not counting rings that are dropped.
+	ClosedRings int
+	// DuplicatePoints is the number of consecutive repeated coordinates
+	// removed.
+	DuplicatePoints int
+	// DroppedRings is the number of rings dropped for having fewer than 4
+	// coordinates once closed, not counting the holes of dropped polygons.
+	DroppedRings int
+	// DroppedPolygons is the number of polygons dropped because their
+	// exterior ring was dropped or they had no rings.
+	DroppedPolygons int
+}
+
+// RepairRings cleans up the parts of a multipolygon before labelling: every
+// ring is closed, consecutive repeated coordinates are removed, and rings
+// with fewer than 4 coordinates once closed, which have no area, are dropped.
+// A polygon whose exterior ring is dropped is dropped along with its holes.
+// The polygons are not modified, and the report counts what was repaired.
+func RepairRings(polygons []Polygon) ([]Polygon, RepairReport) {
+	var report RepairReport
+	var repaired []Polygon
+	for _, polygon := range polygons {
+		var rings Polygon
+		for i, ring := range polygon {
+			ring, closed := repairRing(ring, &report)
+			if len(ring) < 4 {
+				if i == 0 {
+					break
+				}
+				report.DroppedRings++
+				continue
+			}
+			if closed {
+				report.ClosedRings++
+			}
+			rings = append(rings, ring)
+		}
+		if len(rings) == 0 {
+			report.DroppedPolygons++
+			continue
+		}
+		repaired = append(repaired, rings)
+	}
+	return repaired, report
+}
+
+// get a copy of ring without consecutive repeated coordinates, closed, and
+// whether it had to be closed
+func repairRing(ring Ring, report *RepairReport) (Ring, bool) {
+	repaired := make(Ring, 0, len(ring)+1)
+	for _, c := range ring {
+		if len(repaired) > 0 && repaired[len(repaired)-1] == c {
+			report.DuplicatePoints++
+			continue
+		}
+		repaired = append(repaired, c)
+	}
+	if len(repaired) > 0 && repaired[0] != repaired[len(repaired)-1] {
+		return append(repaired, repaired[0]), true
+	}
+	return repaired, false
+}
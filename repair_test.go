@@ -0,0 +1,52 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepairRings(t *testing.T) {
+	polygons := []Polygon{
+		{
+			// open, with a repeated vertex
+			Ring{{0, 0}, {10, 0}, {10, 0}, {10, 10}, {0, 10}},
+			// a hole that is only a line
+			Ring{{2, 2}, {4, 4}, {2, 2}},
+			// a hole closed twice over
+			Ring{{5, 5}, {5, 6}, {6, 6}, {5, 5}, {5, 5}},
+		},
+		// an exterior with no area takes its hole with it
+		{Ring{{0, 0}, {1, 1}}, Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		{},
+	}
+	repaired, report := RepairRings(polygons)
+	expected := []Polygon{{
+		Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		Ring{{5, 5}, {5, 6}, {6, 6}, {5, 5}},
+	}}
+	if !reflect.DeepEqual(repaired, expected) {
+		t.Errorf("Received %v, expected %v", repaired, expected)
+	}
+	// the open exterior that is dropped is not counted as closed
+	AssertEqual(t, report, RepairReport{ClosedRings: 1, DuplicatePoints: 2, DroppedRings: 1, DroppedPolygons: 2})
+
+	// the input is left alone
+	AssertEqual(t, len(polygons[0][0]), 5)
+
+	// clean polygons come through unchanged
+	clean := []Polygon{expected[0]}
+	repaired, report = RepairRings(clean)
+	if !reflect.DeepEqual(repaired, clean) {
+		t.Errorf("Received %v, expected %v", repaired, clean)
+	}
+	AssertEqual(t, report, RepairReport{})
+
+	// and repeated vertices do not change the label
+	water := loadData("test_data/water1.json")
+	repaired, report = RepairRings([]Polygon{water})
+	AssertEqual(t, report, RepairReport{DuplicatePoints: 28})
+	expectedLabel := PolylabelVerbose(water, 1.0)
+	if result := PolylabelVerbose(repaired[0], 1.0); !reflect.DeepEqual(result, expectedLabel) {
+		t.Errorf("Received %v, expected %v", result, expectedLabel)
+	}
+}
@@ -0,0 +1,187 @@
+package polylabel
+
+import (
+	"math"
+	"sort"
+)
+
+// the most samples along the longer side of the bounding box in
+// PolylabelLargestRoom
+const maxRoomSamples = 256
+
+// PolylabelLargestRoom is an experimental variant of PolylabelVerbose that
+// labels the largest room of polygon rather than its deepest point, for
+// shapes such as building footprints with several chambers joined by
+// corridors. The distance field is sampled on a grid, no finer than
+// precision and at most 256 samples along the longer side, and split into
+// rooms by a watershed: flooding down from each peak, two regions that meet
+// are separate rooms unless the lower peak rises less than two samples
+// above where they meet. The label is the best point, to within precision,
+// of the room covering the most samples. If polygon has no interior at the
+// scale of the grid this is the same as PolylabelVerbose.
+func PolylabelLargestRoom(polygon Polygon, precision float64, opts ...Option) Result {
+	o := newOptions(opts)
+	s := newSearch(polygon, precision, o)
+	if s.cellSize == 0 {
+		return s.Result()
+	}
+	grid := newRoomGrid(s, precision)
+	room, ok := grid.largest()
+	if !ok {
+		return PolylabelVerbose(polygon, precision, opts...)
+	}
+
+	scale := o.xScale()
+	accept := o.validator
+	copied := *o
+	copied.validator = func(p Coord, distance float64) bool {
+		if grid.room(p[0]*scale, p[1]) != room {
+			return false
+		}
+		return accept == nil || accept(p, distance)
+	}
+	s = startSearch(polygon, precision, &copied, nil)
+	s.Run(0)
+	return s.Result()
+}
+
+// the distance field of a polygon sampled at the centers of a grid of
+// square cells, split into rooms
+type roomGrid struct {
+	minX    float64
+	minY    float64
+	spacing float64
+	columns int
+	rows    int
+	labels  []int // sample labels, or -1 outside the polygon
+	parent  []int // union-find over labels
+	peak    []float64
+	area    []int
+}
+
+// sample the distance field over the bounding box of a search and flood it
+func newRoomGrid(s *Search, precision float64) *roomGrid {
+	width, height := s.maxX-s.minX, s.maxY-s.minY
+	spacing := math.Max(precision, math.Max(width, height)/maxRoomSamples)
+	g := &roomGrid{
+		minX:    s.minX,
+		minY:    s.minY,
+		spacing: spacing,
+		columns: cellCount(width, spacing),
+		rows:    cellCount(height, spacing),
+	}
+
+	distance := s.o.distance(s.polygon)
+	n := g.columns * g.rows
+	distances := make([]float64, n)
+	var inside []int
+	for i := 0; i < n; i++ {
+		x, y := g.center(i)
+		distances[i] = distance(x, y)
+		if distances[i] > 0 {
+			inside = append(inside, i)
+		}
+	}
+	sort.SliceStable(inside, func(a, b int) bool {
+		return distances[inside[a]] > distances[inside[b]]
+	})
+
+	// flood from the highest samples down, each joining the neighbouring
+	// room with the highest peak and merging rooms that meet below a peak
+	// too shallow to stand apart
+	g.labels = make([]int, n)
+	for i := range g.labels {
+		g.labels[i] = -1
+	}
+	for _, i := range inside {
+		d := distances[i]
+		var rooms []int
+		for _, j := range g.neighbours(i) {
+			if g.labels[j] >= 0 {
+				rooms = append(rooms, g.find(g.labels[j]))
+			}
+		}
+		if len(rooms) == 0 {
+			g.labels[i] = len(g.parent)
+			g.parent = append(g.parent, len(g.parent))
+			g.peak = append(g.peak, d)
+			g.area = append(g.area, 1)
+			continue
+		}
+		top := rooms[0]
+		for _, r := range rooms[1:] {
+			if g.peak[r] > g.peak[top] {
+				top = r
+			}
+		}
+		for _, r := range rooms {
+			if r = g.find(r); r != top && g.peak[r]-d < 2*spacing {
+				g.parent[r] = top
+				g.area[top] += g.area[r]
+			}
+		}
+		g.labels[i] = top
+		g.area[top]++
+	}
+	return g
+}
+
+// get the center of sample i
+func (g *roomGrid) center(i int) (float64, float64) {
+	column, row := i%g.columns, i/g.columns
+	return g.minX + (float64(column)+0.5)*g.spacing, g.minY + (float64(row)+0.5)*g.spacing
+}
+
+// get the samples next to sample i in each direction
+func (g *roomGrid) neighbours(i int) []int {
+	column, row := i%g.columns, i/g.columns
+	var neighbours []int
+	if column > 0 {
+		neighbours = append(neighbours, i-1)
+	}
+	if column < g.columns-1 {
+		neighbours = append(neighbours, i+1)
+	}
+	if row > 0 {
+		neighbours = append(neighbours, i-g.columns)
+	}
+	if row < g.rows-1 {
+		neighbours = append(neighbours, i+g.columns)
+	}
+	return neighbours
+}
+
+// get the room a label has been merged into
+func (g *roomGrid) find(label int) int {
+	for g.parent[label] != label {
+		g.parent[label] = g.parent[g.parent[label]]
+		label = g.parent[label]
+	}
+	return label
+}
+
+// get the room of the sample nearest a point, or -1 if it is outside the
+// polygon or the grid
+func (g *roomGrid) room(x float64, y float64) int {
+	column := int(math.Floor((x - g.minX) / g.spacing))
+	row := int(math.Floor((y - g.minY) / g.spacing))
+	if column < 0 || column >= g.columns || row < 0 || row >= g.rows {
+		return -1
+	}
+	label := g.labels[row*g.columns+column]
+	if label < 0 {
+		return -1
+	}
+	return g.find(label)
+}
+
+// get the room covering the most samples, reporting false if there are none
+func (g *roomGrid) largest() (int, bool) {
+	best := -1
+	for label := range g.parent {
+		if g.find(label) == label && (best < 0 || g.area[label] > g.area[best]) {
+			best = label
+		}
+	}
+	return best, best >= 0
+}
@@ -0,0 +1,41 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelLargestRoom(t *testing.T) {
+	// a square room 8 across joined by a corridor to a hall 6 across and 30
+	// long, which is shallower but larger
+	polygon := Polygon{Ring{
+		Coord{0, 0}, Coord{8, 0}, Coord{8, 3}, Coord{12, 3}, Coord{12, 1}, Coord{42, 1},
+		Coord{42, 7}, Coord{12, 7}, Coord{12, 5}, Coord{8, 5}, Coord{8, 8}, Coord{0, 8}, Coord{0, 0},
+	}}
+	deepest := PolylabelVerbose(polygon, 0.1)
+	if deepest.X > 8 {
+		t.Fatalf("Received %v, expected a label in the square room", deepest)
+	}
+	result := PolylabelLargestRoom(polygon, 0.1)
+	if result.Rejected || result.X < 12 || result.Distance < 2.9 {
+		t.Errorf("Received %v, expected a label in the hall", result)
+	}
+
+	// also when the X axis is stretched
+	result = PolylabelLargestRoom(polygon, 0.1, WithPrecisionXY(0.05, 0.1))
+	if result.Rejected || result.X < 12 || result.Distance < 2.9 {
+		t.Errorf("Received %v, expected a label in the hall", result)
+	}
+
+	// a polygon with a single room is labelled as usual
+	square := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	if result, expected := PolylabelLargestRoom(square, 0.1), PolylabelVerbose(square, 0.1); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	// as is one with no interior
+	line := Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{0, 0}}}
+	if result, expected := PolylabelLargestRoom(line, 0.1), PolylabelVerbose(line, 0.1); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
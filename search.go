@@ -0,0 +1,306 @@
+package polylabel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const searchSnapshotVersion = 1
+
+// Search is a polylabel search that can be run in slices and checkpointed
+// between them, for polygons too large to label within a single time budget.
+// A Search is not safe for concurrent use.
+type Search struct {
+	polygon   Polygon
+	distance  func(x, y float64) float64 // the signed distance from the outline
+	precision float64
+	o         *options
+	minX      float64
+	minY      float64
+	maxX      float64
+	maxY      float64
+	cellSize  float64
+	cells     *cellSearch // nil if the polygon has no area
+
+	simplified bool // whether WithMaxVertices simplified the polygon
+}
+
+// NewSearch prepares a search for the pole of inaccessibility of polygon. The
+// arguments are as for Polylabel. No cells are subdivided until Run is called.
+func NewSearch(polygon Polygon, precision float64, opts ...Option) *Search {
+	return startSearch(polygon, precision, newOptions(opts), nil)
+}
+
+// set up a search and seed it with the initial cells, building the queue in
+// scratch, which may be nil
+func startSearch(polygon Polygon, precision float64, o *options, scratch cellQueue) *Search {
+	s := newSearch(polygon, precision, o)
+	if s.cellSize == 0 {
+		return s
+	}
+
+	cx, cy := getCentroid(s.polygon)
+	cellAt := s.o.cellFunc(s.polygon)
+	if s.o.convexCentroid {
+		if c := cellAt(cx, cy, 0); c.d > s.o.convexMinDistance && len(s.polygon) == 1 && isConvex(s.polygon[0]) {
+			s.cells = &cellSearch{queue: scratch[:0], best: c, precision: s.precision, cellAt: cellAt, o: s.o}
+			return s
+		}
+	}
+	s.seed(cellAt, cx, cy, scratch)
+	return s
+}
+
+// seed a search over its bounding box, starting from the centroid cx, cy
+func (s *Search) seed(cellAt cellFunc, cx float64, cy float64, scratch cellQueue) {
+	precision := s.precision
+
+	// take centroid as the first best guess
+	bestCell := cellAt(cx, cy, 0)
+
+	// special case for rectangular polygons, preferred over the centroid on
+	// a tie as for any other cell
+	if s.o.bboxCell {
+		bboxCell := cellAt((s.minX+s.maxX)/2, (s.minY+s.maxY)/2, 0)
+		if bboxCell.d >= bestCell.d {
+			bestCell = bboxCell
+		}
+	}
+
+	// then any guess that does better still
+	if s.o.initialGuess {
+		guessCell := cellAt(s.o.guess[0]*s.o.xScale(), s.o.guess[1], 0)
+		if guessCell.d > bestCell.d && s.o.accepts(guessCell) {
+			bestCell = guessCell
+		}
+	}
+
+	// no cell can be split finer than precision once it is as narrow as the
+	// polygon, so there is nothing to search
+	if s.cellSize <= precision {
+		s.cells = &cellSearch{queue: scratch[:0], best: bestCell, precision: precision, cellAt: cellAt, o: s.o}
+	} else {
+		s.cells = newCellSearch(s.minX, s.minY, s.maxX, s.maxY, precision, bestCell, cellAt, s.o, scratch)
+	}
+	s.cells.validateBest()
+}
+
+// set up a search without seeding any cells
+func newSearch(polygon Polygon, precision float64, o *options) *Search {
+	// a ring such as {a, b, c} that does not repeat its first coordinate is
+	// closed, so that its last edge is not lost
+	polygon = closeRings(polygon)
+	polygon, precision, o = o.stretchX(polygon, precision)
+	// edge weights follow the edges of the original polygon to those that
+	// replace them
+	if o.pruneSpikes {
+		pruned, kept := removeSpikes(polygon)
+		polygon, o = pruned, o.keepEdgeWeights(polygon, kept)
+	}
+	simplified, kept := simplifyToBudget(polygon, o.maxVertices)
+	polygon, o = simplified, o.keepEdgeWeights(polygon, kept)
+	polygon, o = o.orientPolygon(polygon)
+	minX, minY, maxX, maxY := o.boundingBox(polygon)
+	cellSize := math.Min(maxX-minX, maxY-minY)
+	return &Search{
+		polygon:    polygon,
+		distance:   o.distance(polygon),
+		precision:  o.bracketPrecision(precision, cellSize),
+		o:          o,
+		minX:       minX,
+		minY:       minY,
+		maxX:       maxX,
+		maxY:       maxY,
+		cellSize:   cellSize,
+		simplified: kept != nil,
+	}
+}
+
+// Run processes up to maxIterations cells, or all remaining cells if
+// maxIterations is not positive. It reports whether the search has finished.
+func (s *Search) Run(maxIterations int) bool {
+	if s.cells == nil {
+		return true
+	}
+	for i := 0; maxIterations <= 0 || i < maxIterations; i++ {
+		if !s.cells.step() {
+			return true
+		}
+	}
+	return s.Done()
+}
+
+// Done reports whether the search has finished.
+func (s *Search) Done() bool {
+	return s.cells == nil || len(s.cells.queue) == 0
+}
+
+// Result returns the best label found so far. Once the search has finished
+// this is the same as the result of PolylabelVerbose.
+func (s *Search) Result() Result {
+	return s.result(s.precision)
+}
+
+// get the best label found so far, computing tangents and refinements to
+// within precision
+func (s *Search) result(precision float64) Result {
+	result := s.searchResult(precision)
+	result.X, result.Y = s.o.output(result.X, result.Y)
+	return result
+}
+
+// get the best label found so far as for result, in the coordinates of the
+// polygon before the output options apply
+func (s *Search) searchResult(precision float64) Result {
+	scale := s.o.xScale()
+	if s.cells == nil {
+		// the middle of a polygon that is a line or a point
+		return Result{X: midpoint(s.minX, s.maxX) / scale, Y: midpoint(s.minY, s.maxY), Simplified: s.simplified}
+	}
+	bestCell := s.cells.best
+	rejected := false
+	if math.IsInf(bestCell.d, -1) {
+		bestCell, rejected = s.cells.fallback, true
+	} else if s.o.tieBreak {
+		bestCell = s.cells.nearest()
+	}
+	var corners [4]float64
+	if s.o.refine {
+		bestCell, corners = refineCell(bestCell, precision, s.cells.cellAt)
+	}
+	if s.o.clampInside {
+		bestCell = clampInside(bestCell, s.cells.cellAt)
+	}
+	if s.o.snapVertex {
+		bestCell = snapToVertex(bestCell, s.polygon, scale, s.o.snapVertexWithin, s.cells.cellAt)
+	}
+	distance := bestCell.d
+	if s.o.compactness > 0 {
+		// the cell holds the compactness score
+		distance = s.distance(bestCell.x, bestCell.y)
+	}
+	result := Result{
+		X:          bestCell.x / scale,
+		Y:          bestCell.y,
+		Distance:   distance,
+		Score:      labelScore(distance, s.cellSize),
+		Corners:    corners,
+		Simplified: s.simplified,
+		Rejected:   rejected,
+	}
+	if s.o.tangents {
+		result.Tangents = tangentPoints(bestCell.x, bestCell.y, s.polygon, precision)
+		for i := range result.Tangents {
+			result.Tangents[i][0] /= scale
+		}
+	}
+	if s.o.aspect {
+		result.Aspect = localAspect(bestCell.x, bestCell.y, precision, s.cells.cellAt) / scale
+	}
+	return result
+}
+
+// MarshalBinary encodes the state of the search so that it can be resumed
+// later with RestoreSearch. The polygon itself is not included.
+func (s *Search) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(searchSnapshotVersion)
+	values := []float64{s.precision, s.cellSize}
+	var cells []cell
+	if s.cells != nil {
+		cells = append(cells, s.cells.best)
+		cells = append(cells, s.cells.queue...)
+	}
+	for _, c := range cells {
+		values = append(values, c.x, c.y, c.h, c.d, c.max)
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint64(len(cells))); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RestoreSearch resumes a search from the output of MarshalBinary. polygon
+// and opts must be the same as those the search was started with.
+func RestoreSearch(polygon Polygon, data []byte, opts ...Option) (*Search, error) {
+	r := bytes.NewReader(data)
+	version, err := r.ReadByte()
+	if err != nil {
+		return nil, errors.New("polylabel: empty search snapshot")
+	}
+	if version != searchSnapshotVersion {
+		return nil, errors.New("polylabel: unsupported search snapshot version")
+	}
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, errors.New("polylabel: truncated search snapshot")
+	}
+	if n > uint64(r.Len())/(5*8) {
+		return nil, errors.New("polylabel: truncated search snapshot")
+	}
+	values := make([]float64, 2+5*n)
+	if err := binary.Read(r, binary.LittleEndian, values); err != nil {
+		return nil, errors.New("polylabel: truncated search snapshot")
+	}
+
+	s := newSearch(polygon, values[0], newOptions(opts))
+	if s.cellSize != values[1] {
+		return nil, errors.New("polylabel: search snapshot does not match polygon")
+	}
+	if s.cellSize == 0 {
+		return s, nil
+	}
+	if n == 0 {
+		return nil, errors.New("polylabel: search snapshot has no best cell")
+	}
+
+	cells := make([]cell, n)
+	for i := range cells {
+		v := values[2+5*i:]
+		cells[i] = cell{v[0], v[1], v[2], v[3], v[4]}
+	}
+	s.cells = &cellSearch{
+		queue:     make(cellQueue, 0, n-1),
+		best:      cells[0],
+		precision: s.precision,
+		center:    s.o.tieCenter(s.minX, s.minY, s.maxX, s.maxY),
+		cellAt:    s.o.cellFunc(s.polygon),
+		o:         s.o,
+	}
+	// the queue was saved in heap order, which init leaves unchanged
+	s.cells.queue = append(s.cells.queue, cells[1:]...)
+	s.cells.queue.init(s.o.greedy)
+	s.cells.stats.PeakQueue = len(s.cells.queue)
+	s.cells.fallback = noCell
+	return s, nil
+}
+
+// InitialEstimate returns the best of the candidates a search starts from: the
+// centroid, the center of the bounding box and the centers of the initial grid
+// of cells covering it. This gives a cheap lower bound on the distance that
+// the full search can achieve, for example to decide which of a large number
+// of polygons are worth labeling.
+func InitialEstimate(polygon Polygon, opts ...Option) (Coord, float64) {
+	s := NewSearch(polygon, 0, opts...)
+	scale := s.o.xScale()
+	if s.cells == nil {
+		return Coord{midpoint(s.minX, s.maxX) / scale, midpoint(s.minY, s.maxY)}, 0
+	}
+	best := s.cells.best
+	for _, c := range s.cells.queue {
+		if c.d > best.d {
+			best = c
+		}
+	}
+	return Coord{best.x / scale, best.y}, best.d
+}
+
+// get the point halfway between a and b, which is a itself if they are equal
+func midpoint(a float64, b float64) float64 {
+	return a + (b-a)/2
+}
@@ -0,0 +1,339 @@
+package polylabel
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSearchResume(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+
+	search := NewSearch(polygon, 1.0)
+	slices := 0
+	for !search.Run(10) {
+		data, err := search.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		search, err = RestoreSearch(polygon, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices++
+	}
+	if slices == 0 {
+		t.Error("Expected the search to take more than one slice")
+	}
+	if !reflect.DeepEqual(search.Result(), expected) {
+		t.Errorf("Received %v, expected %v", search.Result(), expected)
+	}
+}
+
+func TestRestoreSearchErrors(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	data, err := NewSearch(polygon, 1.0).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RestoreSearch(polygon, nil); err == nil {
+		t.Error("Expected an error for an empty snapshot")
+	}
+	if _, err := RestoreSearch(polygon, data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for a truncated snapshot")
+	}
+	other := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	if _, err := RestoreSearch(other, data); err == nil {
+		t.Error("Expected an error for a different polygon")
+	}
+}
+
+func TestSubPrecisionPolygon(t *testing.T) {
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{0.75, 0}, Coord{0, 0.375}, Coord{0, 0}}}
+
+	search := NewSearch(polygon, 1.0)
+	if !search.Done() {
+		t.Error("Expected no cells to search for a polygon smaller than the precision")
+	}
+	result := search.Result()
+	AssertEqual(t, result.X, 0.25)
+	AssertEqual(t, result.Y, 0.125)
+
+	// one side within precision is enough to skip the search
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{3, 0}, Coord{0, 0.6}, Coord{0, 0}}}
+	if !NewSearch(polygon, 1.0).Done() {
+		t.Error("Expected no cells to search for a polygon narrower than the precision")
+	}
+	if NewSearch(polygon, 0.5).Done() {
+		t.Error("Expected cells to search for a polygon wider than the precision")
+	}
+}
+
+func TestInitialEstimate(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	point, distance := InitialEstimate(polygon)
+	result := PolylabelVerbose(polygon, 1.0)
+	if distance <= 0 || distance > result.Distance {
+		t.Errorf("Received distance %v, expected a lower bound on %v", distance, result.Distance)
+	}
+	AssertEqual(t, pointToPolygonDistance(point[0], point[1], polygon), distance)
+
+	polygon = Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{2, 0}, Coord{0, 0}}}
+	point, distance = InitialEstimate(polygon)
+	AssertEqual(t, point, Coord{1, 0})
+	AssertEqual(t, distance, 0.0)
+}
+
+func TestPrecisionEqualToBoundingBox(t *testing.T) {
+	cases := []struct {
+		name      string
+		polygon   Polygon
+		precision float64
+		cells     int // initial cells, or -1 if the search is skipped
+	}{
+		{"width equals precision", Polygon{Ring{Coord{0, 0}, Coord{2, 0}, Coord{2, 6}, Coord{0, 6}, Coord{0, 0}}}, 2, -1},
+		{"height equals precision", Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{6, 2}, Coord{0, 2}, Coord{0, 0}}}, 2, -1},
+		{"cell size just above precision", Polygon{Ring{Coord{0, 0}, Coord{6, 0}, Coord{6, 2}, Coord{0, 2}, Coord{0, 0}}}, 1.999, 3},
+		{"fractional cell size", Polygon{Ring{Coord{0.1, 0.1}, Coord{0.4, 0.1}, Coord{0.4, 0.2}, Coord{0.1, 0.2}, Coord{0.1, 0.1}}}, 0.01, 3},
+	}
+	for _, c := range cases {
+		search := NewSearch(c.polygon, c.precision)
+		if c.cells < 0 {
+			if !search.Done() {
+				t.Errorf("%s: Expected no cells to search", c.name)
+			}
+		} else {
+			AssertEqual(t, len(search.cells.queue), c.cells)
+		}
+
+		// the rectangle center is found either way
+		minX, minY, maxX, maxY := boundingBox(c.polygon)
+		result := PolylabelVerbose(c.polygon, c.precision)
+		if math.Abs(result.X-(minX+maxX)/2) > 1e-9 || math.Abs(result.Y-(minY+maxY)/2) > 1e-9 {
+			t.Errorf("%s: Received %v, %v, expected the center", c.name, result.X, result.Y)
+		}
+	}
+}
+
+func TestWithRelativeStop(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	exact, exactStats := PolylabelStats(polygon, 1e-9)
+
+	result, stats := PolylabelStats(polygon, 0, WithRelativeStop(1e-6, 50))
+	if stats.Subdivisions >= exactStats.Subdivisions {
+		t.Errorf("Received %v subdivisions, expected fewer than %v", stats.Subdivisions, exactStats.Subdivisions)
+	}
+	if result.Distance < exact.Distance*(1-1e-6) {
+		t.Errorf("Received %v, expected close to %v", result.Distance, exact.Distance)
+	}
+
+	// the search always runs for at least a window
+	search := NewSearch(polygon, 0, WithRelativeStop(1e6, 10))
+	AssertEqual(t, search.Run(10), false)
+	AssertEqual(t, search.Run(1), true)
+	AssertEqual(t, search.Done(), true)
+
+	// without a window the absolute precision alone applies
+	result = PolylabelVerbose(polygon, 1.0, WithRelativeStop(0.5, 0))
+	if expected := PolylabelVerbose(polygon, 1.0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestWithFloat32Output(t *testing.T) {
+	// far from the origin float32 values are 0.0625 apart
+	polygon := Polygon{Ring{Coord{1e6, 1e6}, Coord{1e6 + 90, 1e6}, Coord{1e6 + 30, 1e6 + 40}, Coord{1e6, 1e6}}}
+	exact, exactStats := PolylabelStats(polygon, 1e-9)
+	result, stats := PolylabelStats(polygon, 1e-9, WithFloat32Output(true))
+	if stats.Subdivisions >= exactStats.Subdivisions {
+		t.Errorf("Received %v subdivisions, expected fewer than %v", stats.Subdivisions, exactStats.Subdivisions)
+	}
+	AssertEqual(t, result.X, float64(float32(result.X)))
+	AssertEqual(t, result.Y, float64(float32(result.Y)))
+	if math.Abs(result.X-exact.X) > 0.0625 || math.Abs(result.Y-exact.Y) > 0.0625 {
+		t.Errorf("Received %v, %v, expected within float32 spacing of %v, %v", result.X, result.Y, exact.X, exact.Y)
+	}
+
+	AssertEqual(t, float32ULP(1e6), 0.0625)
+	AssertEqual(t, float32ULP(-1), math.Pow(2, -23))
+}
+
+func TestWithResultValidator(t *testing.T) {
+	// a long rectangle whose best label, at the center, is in a rejected
+	// district covering x < 6
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 2}, Coord{0, 2}, Coord{0, 0}}}
+	calls := 0
+	outsideDistrict := func(p Coord, distance float64) bool {
+		calls++
+		return p[0] >= 6
+	}
+	result := PolylabelVerbose(polygon, 0.01, WithResultValidator(outsideDistrict))
+	if result.Rejected || result.X < 6 || math.Abs(result.Distance-1) > 0.01 {
+		t.Errorf("Received %v, expected an accepted label right of x = 6", result)
+	}
+	if calls == 0 {
+		t.Error("Expected the validator to be called")
+	}
+
+	// a validator that accepts the best label changes nothing
+	expected := PolylabelVerbose(polygon, 0.01)
+	result = PolylabelVerbose(polygon, 0.01, WithResultValidator(func(Coord, float64) bool { return true }))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	// when every candidate is rejected the best of them is returned
+	result = PolylabelVerbose(polygon, 0.1, WithResultValidator(func(Coord, float64) bool { return false }))
+	if !result.Rejected || math.Abs(result.Distance-1) > 0.1 {
+		t.Errorf("Received %v, expected a rejected label", result)
+	}
+}
+
+func TestWithTieBreakToward(t *testing.T) {
+	// every point on the line y = 1 from x = 1 to 9 is as far from the
+	// outline as any other, and the candidates on it are the centers of the
+	// initial cells, 2 apart
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 2}, Coord{0, 2}, Coord{0, 0}}}
+	unbiased := PolylabelVerbose(polygon, 0.01)
+	AssertEqual(t, Coord{unbiased.X, unbiased.Y}, Coord{5, 1})
+
+	for _, c := range []struct {
+		reference Coord
+		expected  float64
+	}{
+		{Coord{8, 1}, 8},
+		{Coord{2.5, 0}, 2.5},
+		{Coord{100, 50}, 9},
+	} {
+		result := PolylabelVerbose(polygon, 0.01, WithTieBreakToward(c.reference))
+		if math.Abs(result.X-c.expected) > 1 || math.Abs(result.Y-1) > 0.01 {
+			t.Errorf("Received %v, %v toward %v, expected near %v, 1", result.X, result.Y, c.reference, c.expected)
+		}
+		if result.Distance < unbiased.Distance-2*0.01 {
+			t.Errorf("Received distance %v, expected within twice precision of %v", result.Distance, unbiased.Distance)
+		}
+	}
+
+	// a label with clearly more room is not given up for the reference
+	polygon = loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+	result := PolylabelVerbose(polygon, 1.0, WithTieBreakToward(Coord{0, 0}))
+	if result.Distance < expected.Distance-2 {
+		t.Errorf("Received %v, expected within 2 of %v", result.Distance, expected.Distance)
+	}
+}
+
+func TestWithGreedySearch(t *testing.T) {
+	// run to completion the search gives the same label
+	for _, filename := range []string{"test_data/water1.json", "test_data/water2.json"} {
+		polygon := loadData(filename)
+		expected := PolylabelVerbose(polygon, 1.0)
+		if result := PolylabelVerbose(polygon, 1.0, WithGreedySearch(true)); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v, expected %v", result, expected)
+		}
+		results := PolylabelMulti(polygon, []float64{1.0, 10.0}, WithGreedySearch(true))
+		if math.Abs(results[1].Distance-expected.Distance) > 10 {
+			t.Errorf("Received %v, expected within 10 of %v", results[1].Distance, expected.Distance)
+		}
+	}
+
+	// but finds a good label in fewer steps
+	polygon := loadData("test_data/water1.json")
+	bound := NewSearch(polygon, 1.0)
+	bound.Run(10)
+	greedy := NewSearch(polygon, 1.0, WithGreedySearch(true))
+	greedy.Run(10)
+	if greedy.Result().Distance <= bound.Result().Distance {
+		t.Errorf("Received %v after 10 steps, expected more than %v", greedy.Result().Distance, bound.Result().Distance)
+	}
+}
+
+func TestWithPrecisionBracket(t *testing.T) {
+	o := newOptions([]Option{WithPrecisionBracket(0.1, 10)})
+	AssertEqual(t, o.bracketPrecision(1, 1000), 1.0)    // within the bracket
+	AssertEqual(t, o.bracketPrecision(50, 1000), 10.0)  // too coarse
+	AssertEqual(t, o.bracketPrecision(50, 32), 2.0)     // coarse for the polygon
+	AssertEqual(t, o.bracketPrecision(50, 0.8), 0.1)    // but not below finest
+	AssertEqual(t, o.bracketPrecision(0.01, 1000), 0.1) // too fine
+	AssertEqual(t, newOptions(nil).bracketPrecision(50, 0.8), 50.0)
+
+	// a polygon small beside the target is searched rather than skipped
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 1}, Coord{1, 1}, Coord{1, 4}, Coord{0, 4}, Coord{0, 0}}}
+	skipped := PolylabelVerbose(polygon, 100)
+	result := PolylabelVerbose(polygon, 100, WithPrecisionBracket(0.01, 100))
+	expected := PolylabelVerbose(polygon, 0.25)
+	if !reflect.DeepEqual(result, expected) || result.Distance <= skipped.Distance {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestWithBoundFactor(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected, stats := PolylabelStats(polygon, 1.0)
+	for _, factor := range []float64{math.Sqrt2, 0, -1} {
+		if result := PolylabelVerbose(polygon, 1.0, WithBoundFactor(factor)); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v with factor %v, expected %v", result, factor, expected)
+		}
+	}
+
+	// a looser bound does more work for a label as good, and a tighter one
+	// less
+	loose, looseStats := PolylabelStats(polygon, 1.0, WithBoundFactor(2))
+	if loose.Distance < expected.Distance-1 || looseStats.Subdivisions <= stats.Subdivisions {
+		t.Errorf("Received %v, %+v, expected a label as good for more work than %+v", loose, looseStats, stats)
+	}
+	_, tightStats := PolylabelStats(polygon, 1.0, WithBoundFactor(1))
+	if tightStats.Subdivisions >= stats.Subdivisions {
+		t.Errorf("Received %+v, expected less work than %+v", tightStats, stats)
+	}
+}
+
+func TestWithApproximateBelow(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected, stats := PolylabelStats(polygon, 0.01)
+	for _, size := range []float64{1, 4} {
+		result, approx := PolylabelStats(polygon, 0.01, WithApproximateBelow(size))
+		if result.Distance < expected.Distance-math.Sqrt2*size || approx.Subdivisions >= stats.Subdivisions {
+			t.Errorf("Received %v, %+v with size %v, expected a label within %v of %v for less work than %+v",
+				result, approx, size, math.Sqrt2*size, expected, stats)
+		}
+	}
+
+	// a size below precision changes nothing
+	if result := PolylabelVerbose(polygon, 1.0, WithApproximateBelow(0.1)); !reflect.DeepEqual(result, PolylabelVerbose(polygon, 1.0)) {
+		t.Errorf("Received %v, expected %v", result, PolylabelVerbose(polygon, 1.0))
+	}
+}
+
+func BenchmarkWithApproximateBelow(b *testing.B) {
+	polygon := loadData("test_data/water2.json")
+	for _, size := range []float64{0, 1, 10} {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Polylabel(polygon, 0.01, WithApproximateBelow(size))
+			}
+		})
+	}
+}
+
+func TestWithInitialGuess(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected, stats := PolylabelStats(polygon, 10.0)
+
+	// a guess at the label prunes more cells straight away
+	result, guessStats := PolylabelStats(polygon, 10.0, WithInitialGuess(Coord{expected.X, expected.Y}))
+	if !reflect.DeepEqual(result, expected) || guessStats.Subdivisions >= stats.Subdivisions {
+		t.Errorf("Received %v, %+v, expected the same label for less work than %+v", result, guessStats, stats)
+	}
+
+	// poor guesses, inside or outside the polygon, change nothing
+	for _, guess := range []Coord{{polygon[0][0][0], polygon[0][0][1]}, {-1e6, -1e6}} {
+		if result := PolylabelVerbose(polygon, 10.0, WithInitialGuess(guess)); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v with guess %v, expected %v", result, guess, expected)
+		}
+	}
+}
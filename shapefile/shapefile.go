@@ -0,0 +1,250 @@
+// Package shapefile labels the polygon records of an ESRI shapefile.
+//
+// Only the geometry in the .shp file is read. Records are identified by their
+// record number, which matches the row of the accompanying .dbf file.
+package shapefile
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	polylabel "github.com/snorfalorpagus/polylabel-go"
+)
+
+const (
+	fileCode     = 9994
+	headerLength = 100
+
+	shapeNull     = 0
+	shapePolygon  = 5
+	shapePolygonZ = 15
+	shapePolygonM = 25
+)
+
+// Label is the label of a single shapefile record.
+type Label struct {
+	// Record is the 1-based record number from the shapefile.
+	Record   int
+	X        float64
+	Y        float64
+	Distance float64
+}
+
+// Read reads the polygon records of a .shp file. Each record may hold several
+// polygons, which are told apart by the winding order of their rings: outer
+// rings are clockwise and holes are counter-clockwise. Records with a null
+// shape give an empty slice.
+func Read(r io.Reader) ([][]polylabel.Polygon, error) {
+	header := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("shapefile: reading header: %v", err)
+	}
+	if binary.BigEndian.Uint32(header[0:4]) != fileCode {
+		return nil, errors.New("shapefile: not a shapefile")
+	}
+	switch binary.LittleEndian.Uint32(header[32:36]) {
+	case shapeNull, shapePolygon, shapePolygonZ, shapePolygonM:
+	default:
+		return nil, errors.New("shapefile: not a polygon shapefile")
+	}
+
+	var records [][]polylabel.Polygon
+	recordHeader := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, recordHeader); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("shapefile: reading record %d: %v", len(records)+1, err)
+		}
+		content := make([]byte, 2*binary.BigEndian.Uint32(recordHeader[4:8]))
+		if _, err := io.ReadFull(r, content); err != nil {
+			return nil, fmt.Errorf("shapefile: reading record %d: %v", len(records)+1, err)
+		}
+		polygons, err := decodeRecord(content)
+		if err != nil {
+			return nil, fmt.Errorf("shapefile: record %d: %v", len(records)+1, err)
+		}
+		records = append(records, polygons)
+	}
+}
+
+func decodeRecord(content []byte) ([]polylabel.Polygon, error) {
+	if len(content) < 4 {
+		return nil, errors.New("truncated record")
+	}
+	switch binary.LittleEndian.Uint32(content[0:4]) {
+	case shapeNull:
+		return nil, nil
+	case shapePolygon, shapePolygonZ, shapePolygonM:
+	default:
+		return nil, errors.New("not a polygon")
+	}
+
+	// the Z and M values follow the points and are ignored
+	if len(content) < 44 {
+		return nil, errors.New("truncated record")
+	}
+	numParts := int(binary.LittleEndian.Uint32(content[36:40]))
+	numPoints := int(binary.LittleEndian.Uint32(content[40:44]))
+	partsStart := 44
+	pointsStart := partsStart + 4*numParts
+	if numParts < 0 || numPoints < 0 || len(content) < pointsStart+16*numPoints {
+		return nil, errors.New("truncated record")
+	}
+
+	var rings []polylabel.Ring
+	for i := 0; i < numParts; i++ {
+		start := int(binary.LittleEndian.Uint32(content[partsStart+4*i:]))
+		end := numPoints
+		if i+1 < numParts {
+			end = int(binary.LittleEndian.Uint32(content[partsStart+4*(i+1):]))
+		}
+		if start < 0 || end > numPoints || start >= end {
+			return nil, errors.New("invalid part offsets")
+		}
+		ring := make(polylabel.Ring, end-start)
+		for j := range ring {
+			offset := pointsStart + 16*(start+j)
+			ring[j] = polylabel.Coord{
+				math.Float64frombits(binary.LittleEndian.Uint64(content[offset:])),
+				math.Float64frombits(binary.LittleEndian.Uint64(content[offset+8:])),
+			}
+		}
+		rings = append(rings, ring)
+	}
+	return groupRings(rings), nil
+}
+
+// group rings into polygons, with each counter-clockwise hole assigned to the
+// first clockwise outer ring that contains it
+func groupRings(rings []polylabel.Ring) []polylabel.Polygon {
+	var polygons []polylabel.Polygon
+	var holes []polylabel.Ring
+	for _, ring := range rings {
+		if signedArea(ring) <= 0 {
+			polygons = append(polygons, polylabel.Polygon{ring})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+	for _, hole := range holes {
+		assigned := false
+		for i, polygon := range polygons {
+			if ringContains(polygon[0], hole[0]) {
+				polygons[i] = append(polygon, hole)
+				assigned = true
+				break
+			}
+		}
+		// a hole outside every outer ring is really an outer ring with the
+		// wrong winding order
+		if !assigned {
+			polygons = append(polygons, polylabel.Polygon{hole})
+		}
+	}
+	return polygons
+}
+
+// twice the signed area of a ring, positive if counter-clockwise
+func signedArea(ring polylabel.Ring) float64 {
+	area := 0.0
+	for n := 0; n < len(ring)-1; n++ {
+		a, b := ring[n], ring[n+1]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area
+}
+
+func ringContains(ring polylabel.Ring, p polylabel.Coord) bool {
+	inside := false
+	for n := 0; n < len(ring)-1; n++ {
+		a, b := ring[n], ring[n+1]
+		if ((a[1] > p[1]) != (b[1] > p[1])) && (p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0]) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// LabelRecords reads a .shp file and labels each of its polygon records. For records
+// holding several polygons the label with the greatest distance is used.
+// Records with a null shape are skipped.
+func LabelRecords(r io.Reader, precision float64, opts ...polylabel.Option) ([]Label, error) {
+	records, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	var labels []Label
+	for i, polygons := range records {
+		if len(polygons) == 0 {
+			continue
+		}
+		label := Label{Record: i + 1, Distance: math.Inf(-1)}
+		for _, polygon := range polygons {
+			result := polylabel.PolylabelVerbose(polygon, precision, opts...)
+			if result.Distance > label.Distance {
+				label.X, label.Y, label.Distance = result.X, result.Y, result.Distance
+			}
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// WriteCSV writes labels as CSV with a header row of record, x, y, distance.
+func WriteCSV(w io.Writer, labels []Label) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"record", "x", "y", "distance"}); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		err := cw.Write([]string{
+			strconv.Itoa(label.Record),
+			strconv.FormatFloat(label.X, 'g', -1, 64),
+			strconv.FormatFloat(label.Y, 'g', -1, 64),
+			strconv.FormatFloat(label.Distance, 'g', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// WriteGeoJSON writes labels as a GeoJSON FeatureCollection of points with
+// record and distance properties.
+func WriteGeoJSON(w io.Writer, labels []Label) error {
+	features := make([]geoJSONFeature, len(labels))
+	for i, label := range labels {
+		features[i] = geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONPoint{"Point", [2]float64{label.X, label.Y}},
+			Properties: map[string]interface{}{
+				"record":   label.Record,
+				"distance": label.Distance,
+			},
+		}
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+}
@@ -0,0 +1,117 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	polylabel "github.com/snorfalorpagus/polylabel-go"
+)
+
+// build a polygon shapefile with one record per slice of rings
+func buildShapefile(records [][]polylabel.Ring) []byte {
+	var body bytes.Buffer
+	for i, rings := range records {
+		var content bytes.Buffer
+		le := func(v interface{}) { binary.Write(&content, binary.LittleEndian, v) }
+		if rings == nil {
+			le(int32(shapeNull))
+		} else {
+			le(int32(shapePolygon))
+			le([4]float64{})
+			numPoints := 0
+			for _, ring := range rings {
+				numPoints += len(ring)
+			}
+			le(int32(len(rings)))
+			le(int32(numPoints))
+			offset := 0
+			for _, ring := range rings {
+				le(int32(offset))
+				offset += len(ring)
+			}
+			for _, ring := range rings {
+				for _, coord := range ring {
+					le(coord)
+				}
+			}
+		}
+		binary.Write(&body, binary.BigEndian, int32(i+1))
+		binary.Write(&body, binary.BigEndian, int32(content.Len()/2))
+		body.Write(content.Bytes())
+	}
+
+	header := make([]byte, headerLength)
+	binary.BigEndian.PutUint32(header[0:], fileCode)
+	binary.BigEndian.PutUint32(header[24:], uint32((headerLength+body.Len())/2))
+	binary.LittleEndian.PutUint32(header[28:], 1000)
+	binary.LittleEndian.PutUint32(header[32:], shapePolygon)
+	return append(header, body.Bytes()...)
+}
+
+func TestLabelRecords(t *testing.T) {
+	// outer rings are clockwise and holes counter-clockwise
+	square := polylabel.Ring{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}
+	frame := polylabel.Ring{{10, 0}, {10, 10}, {20, 10}, {20, 0}, {10, 0}}
+	hole := polylabel.Ring{{12, 2}, {18, 2}, {18, 8}, {12, 8}, {12, 2}}
+	data := buildShapefile([][]polylabel.Ring{
+		{square},
+		nil,
+		{square, frame, hole},
+	})
+
+	labels, err := LabelRecords(bytes.NewReader(data), 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 {
+		t.Fatalf("Received %v labels, expected 2", len(labels))
+	}
+	if labels[0] != (Label{Record: 1, X: 2, Y: 2, Distance: 2}) {
+		t.Errorf("Received %v for the square", labels[0])
+	}
+	// the square is a better place for a label than the frame around the hole
+	if labels[1] != (Label{Record: 3, X: 2, Y: 2, Distance: 2}) {
+		t.Errorf("Received %v for the multipolygon", labels[1])
+	}
+
+	records, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records[2]) != 2 || len(records[2][1]) != 2 {
+		t.Errorf("Expected the hole to be grouped with the frame, received %v", records[2])
+	}
+
+	var out bytes.Buffer
+	if err := WriteCSV(&out, labels); err != nil {
+		t.Fatal(err)
+	}
+	expected := "record,x,y,distance\n1,2,2,2\n3,2,2,2\n"
+	if out.String() != expected {
+		t.Errorf("Received CSV %q, expected %q", out.String(), expected)
+	}
+
+	out.Reset()
+	if err := WriteGeoJSON(&out, labels[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"coordinates":[2,2]`) {
+		t.Errorf("Received GeoJSON %s", out.String())
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := Read(bytes.NewReader(nil)); err == nil {
+		t.Error("Expected an error for an empty file")
+	}
+	data := buildShapefile([][]polylabel.Ring{{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}})
+	if _, err := Read(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Error("Expected an error for a truncated record")
+	}
+	data[3] = 0
+	if _, err := Read(bytes.NewReader(data)); err == nil {
+		t.Error("Expected an error for a bad file code")
+	}
+}
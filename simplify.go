@@ -0,0 +1,122 @@
+package polylabel
+
+import (
+	"math"
+	"sort"
+)
+
+// WithMaxVertices bounds the cost of labelling a polygon with more than n
+// vertices by first simplifying it with the Douglas-Peucker algorithm, using
+// the smallest tolerance that brings it within n vertices, counting the
+// closing vertex of each ring. Every ring keeps at least four vertices, so a
+// budget below four per ring may be exceeded. Result.Simplified reports
+// whether the polygon was simplified, in which case the label is only as
+// accurate as the simplification. Edge weights refer to the edges of the
+// original polygon, and an edge of the simplified polygon that replaces
+// several takes the smallest of their weights. A budget that is not positive
+// disables simplification.
+func WithMaxVertices(n int) Option {
+	return func(o *options) {
+		o.maxVertices = n
+	}
+}
+
+// simplify polygon to at most n vertices if it has more, also returning the
+// indices of the distinct vertices that each ring keeps, or nil if it was not
+// simplified
+func simplifyToBudget(polygon Polygon, n int) (Polygon, [][]int) {
+	total := VertexCount(polygon, false)
+	if n <= 0 || total <= n {
+		return polygon, nil
+	}
+
+	// a vertex is kept by Douglas-Peucker when the tolerance is below its
+	// importance, so keeping the most important vertices meets the budget
+	importance := make([][]float64, len(polygon))
+	var finite []float64
+	fixed := 0
+	for i, ring := range polygon {
+		importance[i] = ringImportance(ring)
+		for _, v := range importance[i] {
+			if math.IsInf(v, 1) {
+				fixed++
+			} else {
+				finite = append(finite, v)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(finite)))
+	threshold := math.Inf(-1)
+	if allowed := n - fixed; allowed < len(finite) {
+		threshold = finite[0]
+		if allowed > 0 {
+			threshold = finite[allowed]
+		}
+	}
+
+	simplified := make(Polygon, len(polygon))
+	kept := make([][]int, len(polygon))
+	for i, ring := range polygon {
+		simplified[i] = make(Ring, 0, len(ring))
+		for j, c := range ring {
+			if importance[i][j] > threshold {
+				simplified[i] = append(simplified[i], c)
+				if j < len(ring)-1 {
+					kept[i] = append(kept[i], j)
+				}
+			}
+		}
+	}
+	return simplified, kept
+}
+
+// get the tolerance below which Douglas-Peucker keeps each vertex of a closed
+// ring. The first and closing vertices, the vertex furthest from them and the
+// next most important vertex are always kept, so that the ring keeps an area.
+func ringImportance(ring Ring) []float64 {
+	importance := make([]float64, len(ring))
+	for i := range importance {
+		importance[i] = math.Inf(1)
+	}
+	last := len(ring) - 1
+	if last < 4 {
+		return importance
+	}
+
+	// split the ring at its first vertex and the vertex furthest from it
+	far := 0
+	farDist := -1.0
+	for j := 1; j < last; j++ {
+		if d := math.Hypot(ring[j][0]-ring[0][0], ring[j][1]-ring[0][1]); d > farDist {
+			far, farDist = j, d
+		}
+	}
+
+	type span struct {
+		from, to int
+		bound    float64 // importance of the vertex that created the span
+	}
+	stack := []span{{0, far, math.Inf(1)}, {far, last, math.Inf(1)}}
+	best, bestImportance := -1, -1.0
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if s.to-s.from < 2 {
+			continue
+		}
+		k, dist := s.from+1, -1.0
+		for j := s.from + 1; j < s.to; j++ {
+			if d := segmentDistanceSquared(ring[j][0], ring[j][1], ring[s.from], ring[s.to]); d > dist {
+				k, dist = j, d
+			}
+		}
+		// a vertex can only be kept if the vertex that created its span is
+		importance[k] = math.Min(math.Sqrt(dist), s.bound)
+		if importance[k] > bestImportance {
+			best, bestImportance = k, importance[k]
+		}
+		stack = append(stack, span{s.from, k, importance[k]}, span{k, s.to, importance[k]})
+	}
+	importance[best] = math.Inf(1)
+	return importance
+}
@@ -0,0 +1,70 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWithMaxVertices(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	total := 0
+	for _, ring := range polygon {
+		total += len(ring)
+	}
+	expected := PolylabelVerbose(polygon, 1.0)
+	AssertEqual(t, expected.Simplified, false)
+
+	// a budget the polygon already meets changes nothing
+	result := PolylabelVerbose(polygon, 1.0, WithMaxVertices(total))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	for _, budget := range []int{total / 2, total / 10} {
+		simplified, kept := simplifyToBudget(polygon, budget)
+		AssertEqual(t, len(kept), len(polygon))
+		count := 0
+		for i, ring := range simplified {
+			count += len(ring)
+			AssertEqual(t, ring[0], polygon[i][0])
+			AssertEqual(t, ring[len(ring)-1], polygon[i][len(polygon[i])-1])
+			if len(ring) < 4 && len(polygon[i]) >= 4 {
+				t.Errorf("Received a ring of %d vertices, expected at least 4", len(ring))
+			}
+		}
+		if count > budget || count < budget*9/10 {
+			t.Errorf("Received %d vertices, expected close to %d", count, budget)
+		}
+
+		result := PolylabelVerbose(polygon, 1.0, WithMaxVertices(budget))
+		AssertEqual(t, result.Simplified, true)
+		if math.Abs(result.Distance-expected.Distance) > 0.1*expected.Distance {
+			t.Errorf("Received %v, expected close to %v", result.Distance, expected.Distance)
+		}
+	}
+}
+
+func TestRingImportance(t *testing.T) {
+	// the bump of 1 is kept for any tolerance below 1
+	ring := Ring{Coord{0, 0}, Coord{5, 1}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}
+	importance := ringImportance(ring)
+	AssertEqual(t, importance[1], 1.0)
+	for _, i := range []int{0, 3, 5} {
+		AssertEqual(t, math.IsInf(importance[i], 1), true)
+	}
+}
+
+func TestWithMaxVerticesEdgeWeights(t *testing.T) {
+	// the bump on the bottom edge is simplified away, and the bottom edge
+	// that replaces its two edges takes the harder of their weights
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{5, 0.01}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	simplified := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	weights := [][]float64{{1, 0.5, 1, 2, 1}}
+	result := PolylabelVerbose(polygon, 0.01, WithMaxVertices(5), WithEdgeWeights(weights))
+	expected := PolylabelVerbose(simplified, 0.01, WithEdgeWeights([][]float64{{0.5, 1, 2, 1}}))
+	expected.Simplified = true
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
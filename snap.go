@@ -0,0 +1,45 @@
+package polylabel
+
+import "math"
+
+// WithSnapToVertex moves the label to the nearest vertex of the polygon once
+// the search has finished, to anchor it to a real node of the outline rather
+// than a free point inside. Result.Distance is then the distance at the
+// vertex, which is zero, and any tangent points are those of the vertex.
+// Output rounding and transforms apply to the vertex as to any label.
+func WithSnapToVertex(enabled bool) Option {
+	return func(o *options) {
+		o.snapVertex = enabled
+		o.snapVertexWithin = 0
+	}
+}
+
+// WithSnapToVertexWithin is like WithSnapToVertex but only moves the label if
+// the nearest vertex is at most maxDistance away, leaving it where the search
+// put it otherwise.
+func WithSnapToVertexWithin(maxDistance float64) Option {
+	return func(o *options) {
+		o.snapVertex = true
+		o.snapVertexWithin = maxDistance
+	}
+}
+
+// move a cell to the nearest vertex of polygon, if that is within the limit
+// set by WithSnapToVertexWithin; x is measured after dividing by scale, as
+// for a polygon stretched by WithPrecisionXY
+func snapToVertex(c cell, polygon Polygon, scale float64, maxDistance float64, cellAt cellFunc) cell {
+	best := math.Inf(1)
+	var vertex Coord
+	for _, ring := range polygon {
+		for _, coord := range ring {
+			dx, dy := (coord[0]-c.x)/scale, coord[1]-c.y
+			if d := dx*dx + dy*dy; d < best {
+				best, vertex = d, coord
+			}
+		}
+	}
+	if math.IsInf(best, 1) || (maxDistance > 0 && best > maxDistance*maxDistance) {
+		return c
+	}
+	return cellAt(vertex[0], vertex[1], 0)
+}
@@ -0,0 +1,38 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWithSnapToVertex(t *testing.T) {
+	// a notch in the top of a square brings a vertex near the middle
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{6, 10}, Coord{5, 6}, Coord{4, 10}, Coord{0, 10}, Coord{0, 0}}}
+	free := PolylabelVerbose(polygon, 0.1)
+	result := PolylabelVerbose(polygon, 0.1, WithSnapToVertex(true))
+	AssertEqual(t, result.X, 5.0)
+	AssertEqual(t, result.Y, 6.0)
+	AssertEqual(t, result.Distance, 0.0)
+
+	// the snap is limited to nearby vertices
+	within := PolylabelVerbose(polygon, 0.1, WithSnapToVertexWithin(math.Hypot(free.X-5, free.Y-6)+0.1))
+	if !reflect.DeepEqual(within, result) {
+		t.Errorf("Received %v, expected %v", within, result)
+	}
+	within = PolylabelVerbose(polygon, 0.1, WithSnapToVertexWithin(1))
+	if !reflect.DeepEqual(within, free) {
+		t.Errorf("Received %v, expected %v", within, free)
+	}
+
+	// and can be turned off again
+	off := PolylabelVerbose(polygon, 0.1, WithSnapToVertexWithin(1), WithSnapToVertex(false))
+	if !reflect.DeepEqual(off, free) {
+		t.Errorf("Received %v, expected %v", off, free)
+	}
+
+	// the vertex is in the coordinates of the polygon when X is stretched
+	result = PolylabelVerbose(polygon, 0.1, WithPrecisionXY(0.1, 0.5), WithSnapToVertex(true))
+	AssertEqual(t, result.X, 5.0)
+	AssertEqual(t, result.Y, 6.0)
+}
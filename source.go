@@ -0,0 +1,146 @@
+package polylabel
+
+import "math"
+
+// RingSource provides the rings of a polygon on demand, so that a polygon
+// too large to hold in memory can be read from storage each time it is
+// needed. Rings calls fn with each ring in turn, exterior ring first. The
+// ring passed to fn is only used during the call, so a source may reuse one
+// buffer for every ring.
+type RingSource interface {
+	Rings(fn func(ring Ring))
+}
+
+// Rings calls fn with each ring of the polygon, so that a Polygon can be used
+// as a RingSource.
+func (polygon Polygon) Rings(fn func(ring Ring)) {
+	for _, ring := range polygon {
+		fn(ring)
+	}
+}
+
+// PolylabelSource is like PolylabelVerbose but reads the polygon from src,
+// holding no more than one ring in memory at a time, and only as long as src
+// does. The rings are read once to find the bounding box and centroid of the
+// exterior, of which only those are kept, and again for every cell
+// evaluated, so each read should be cheap. The options that need the whole
+// polygon are ignored: WithContainmentRule, WithEdgeWeights,
+// WithRingsAsSeparate, WithEnsureCCW, WithRFC7946, WithSpikePruning,
+// WithMaxVertices, WithPreferCentroidWhenConvex, WithTangentPoints,
+// WithSnapToVertex and WithPrecisionXY, which would stretch it. src must be
+// safe for concurrent use if WithParallel is enabled.
+func PolylabelSource(src RingSource, precision float64, opts ...Option) Result {
+	var bounds Rect
+	var cx, cy float64
+	first := true
+	src.Rings(func(ring Ring) {
+		if first && len(ring) > 0 {
+			bounds = Polygon{ring}.Bounds()
+			cx, cy = getCentroid(Polygon{ring})
+			first = false
+		}
+	})
+	return labelDistance(bounds, cx, cy, func(x, y float64) float64 {
+		return pointToSourceDistance(x, y, src)
+	}, precision, opts)
+}
+
+// label a shape known only by its bounding box, its centroid and its
+// distance function, ignoring the options that need the polygon itself
+func labelDistance(bounds Rect, cx float64, cy float64, distance func(x, y float64) float64, precision float64, opts []Option) Result {
+	o := newOptions(opts)
+	o.withoutPolygon()
+	if o.viewport {
+		outline := distance
+		distance = func(x, y float64) float64 {
+			return math.Min(outline(x, y), o.viewportDistance(x, y))
+		}
+	}
+	s := &Search{distance: distance, o: o, minX: bounds.MinX, minY: bounds.MinY, maxX: bounds.MaxX, maxY: bounds.MaxY}
+	s.cellSize = math.Min(s.maxX-s.minX, s.maxY-s.minY)
+	s.precision = o.bracketPrecision(precision, s.cellSize)
+	if s.cellSize > 0 {
+		bound := o.bound()
+		var cellAt cellFunc = func(x, y, h float64) cell {
+			d := distance(x, y)
+			return cell{x, y, h, d, d + h*bound}
+		}
+		if o.compactness > 0 {
+			cellAt = compactCells(cellAt, distance, o.compactness)
+		}
+		s.seed(cellAt, cx, cy, nil)
+	}
+	s.Run(0)
+	return s.Result()
+}
+
+// turn off the options that need the polygon itself, for searches that have
+// only its distance function
+func (o *options) withoutPolygon() {
+	o.tangents = false
+	o.snapVertex = false
+	// the search would be stretched but not the distance function
+	o.precisionX, o.precisionY = 0, 0
+}
+
+// get the bounding box and centroid of a set of exterior rings, skipping any
+// that are empty, or the zero Rect if all are
+func ringsExtent(exteriors []Ring) (bounds Rect, cx float64, cy float64) {
+	var rings []Ring
+	for _, ring := range closeRings(exteriors) {
+		if len(ring) > 0 {
+			rings = append(rings, ring)
+		}
+	}
+	if len(rings) == 0 {
+		return Rect{}, 0, 0
+	}
+	minX, minY, maxX, maxY := ringsBoundingBox(rings)
+	cx, cy = ringsCentroid(rings)
+	return Rect{minX, minY, maxX, maxY}, cx, cy
+}
+
+// get the centroid of a set of rings as the average of their centroids
+// weighted by area
+func ringsCentroid(rings []Ring) (float64, float64) {
+	if len(rings) == 1 {
+		return getCentroid(Polygon{rings[0]})
+	}
+	var x, y, total float64
+	for _, ring := range rings {
+		cx, cy := getCentroid(Polygon{ring})
+		area := math.Abs(ringArea(ring))
+		x += cx * area
+		y += cy * area
+		total += area
+	}
+	if total == 0 {
+		return getCentroid(Polygon{rings[0]})
+	}
+	return x / total, y / total
+}
+
+// signed distance from point to the outline of the polygon read from src
+// (negative if point is outside), as for pointToPolygonDistance; each ring is
+// closed by an edge from its last coordinate back to its first, which has no
+// length if the ring is already closed
+func pointToSourceDistance(x float64, y float64, src RingSource) float64 {
+	inside := false
+	minDistSq := math.Inf(1)
+	src.Rings(func(ring Ring) {
+		for n := range ring {
+			a := ring[n]
+			b := ring[(n+1)%len(ring)]
+			if rayCrosses(x, y, a, b) {
+				inside = !inside
+			}
+			minDistSq = math.Min(minDistSq, segmentDistanceSquared(x, y, a, b))
+		}
+	})
+
+	factor := 1.0
+	if !inside {
+		factor = -1.0
+	}
+	return factor * math.Sqrt(minDistSq)
+}
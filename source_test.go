@@ -0,0 +1,82 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+// a ring source that copies each ring into a shared buffer, as a source
+// reading from storage would
+type bufferedSource struct {
+	polygon Polygon
+	buffer  Ring
+	reads   int
+}
+
+func (s *bufferedSource) Rings(fn func(ring Ring)) {
+	s.reads++
+	for _, ring := range s.polygon {
+		s.buffer = append(s.buffer[:0], ring...)
+		fn(s.buffer)
+	}
+}
+
+func TestPolylabelSource(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+
+	if result := PolylabelSource(polygon, 1.0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	src := &bufferedSource{polygon: polygon}
+	if result := PolylabelSource(src, 1.0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	if src.reads < 2 {
+		t.Errorf("Received %v reads, expected one for the bounds and more for the cells", src.reads)
+	}
+
+	// an empty source is labelled at the origin
+	if result := PolylabelSource(Polygon{}, 1.0); !reflect.DeepEqual(result, Result{}) {
+		t.Errorf("Received %v, expected %v", result, Result{})
+	}
+}
+
+func TestPolylabelSourceOptions(t *testing.T) {
+	// the options that need only the distance agree with PolylabelVerbose
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{0, 4}, Coord{0, 0}}}
+	for _, opt := range []Option{WithViewport(0, 0, 5, 4), WithPrecisionBracket(0.5, 1)} {
+		expected := PolylabelVerbose(polygon, 0.1, opt)
+		if result := PolylabelSource(&bufferedSource{polygon: polygon}, 0.1, opt); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Received %v, expected %v", result, expected)
+		}
+	}
+}
+
+func TestPolylabelSourceIgnoresPrecisionXY(t *testing.T) {
+	// the distance function cannot be stretched, so neither is the search
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 4}, Coord{0, 4}, Coord{0, 0}}}
+	expected := PolylabelSource(polygon, 0.1)
+	result := PolylabelSource(&bufferedSource{polygon: polygon}, 0.1, WithPrecisionXY(1, 2))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	AssertEqual(t, result.X, 5.0)
+	expected, _ = PolylabelInteger(polygon, 0.1)
+	if result, _ = PolylabelInteger(polygon, 0.1, WithPrecisionXY(1, 2)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
+
+func TestPolylabelSourceOpenRing(t *testing.T) {
+	// a ring that does not repeat its first coordinate is closed, as it is by
+	// Polylabel
+	open := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{0, 10}}}
+	expected := PolylabelVerbose(open, 0.01)
+	if result := PolylabelSource(open, 0.01); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	if result := PolylabelSource(&bufferedSource{polygon: open}, 0.01); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
@@ -0,0 +1,98 @@
+package polylabel
+
+import "math"
+
+// edges that turn back on each other with a sine of the angle between them
+// smaller than this are a spike
+const spikeSinEpsilon = 1e-9
+
+// FindSpikes returns the tips of the spikes of polygon: vertices where a ring
+// goes out and straight back along the way it came, enclosing no area. Such
+// needles never contain a good label but stretch the bounding box and add
+// edges to every distance evaluation.
+func FindSpikes(polygon Polygon) []Coord {
+	var tips []Coord
+	for _, ring := range polygon {
+		n := len(ring) - 1
+		for i := 0; i < n; i++ {
+			if isSpike(ring[(i+n-1)%n], ring[i], ring[(i+1)%n]) {
+				tips = append(tips, ring[i])
+			}
+		}
+	}
+	return tips
+}
+
+// RemoveSpikes returns a copy of polygon with its spikes removed, repeatedly,
+// so that a needle drawn with several vertices is removed entirely. Rings
+// left without area keep their remaining vertices. polygon is not modified.
+func RemoveSpikes(polygon Polygon) Polygon {
+	pruned, _ := removeSpikes(polygon)
+	return pruned
+}
+
+// remove the spikes of polygon, also returning the indices of the vertices
+// that each ring keeps
+func removeSpikes(polygon Polygon) (Polygon, [][]int) {
+	pruned := make(Polygon, len(polygon))
+	kept := make([][]int, len(polygon))
+	for i, ring := range polygon {
+		pruned[i], kept[i] = removeRingSpikes(ring)
+	}
+	return pruned, kept
+}
+
+// WithSpikePruning removes spikes from the polygon with RemoveSpikes before
+// labelling it. Edge weights refer to the edges of the original polygon, and
+// an edge of the pruned polygon that replaces several takes the smallest of
+// their weights.
+func WithSpikePruning(enabled bool) Option {
+	return func(o *options) {
+		o.pruneSpikes = enabled
+	}
+}
+
+// report whether the path a, b, c turns straight back at b
+func isSpike(a Coord, b Coord, c Coord) bool {
+	ux, uy := b[0]-a[0], b[1]-a[1]
+	vx, vy := c[0]-b[0], c[1]-b[1]
+	cross := ux*vy - uy*vx
+	dot := ux*vx + uy*vy
+	return dot < 0 && math.Abs(cross) <= spikeSinEpsilon*math.Hypot(ux, uy)*math.Hypot(vx, vy)
+}
+
+// remove the spikes of a closed ring, along with a vertex that would repeat
+// the one before it once a spike is gone, also returning the indices of the
+// distinct vertices kept
+func removeRingSpikes(ring Ring) (Ring, []int) {
+	indices := make([]int, 0, len(ring))
+	for n := 0; n < len(ring)-1; n++ {
+		indices = append(indices, n)
+	}
+	if len(ring) < 4 {
+		return append(Ring(nil), ring...), indices
+	}
+	vertices := append(Ring(nil), ring[:len(ring)-1]...)
+	for changed := true; changed && len(vertices) >= 3; {
+		changed = false
+		for i := 0; i < len(vertices) && len(vertices) >= 3; i++ {
+			n := len(vertices)
+			prev, next := (i+n-1)%n, (i+1)%n
+			if !isSpike(vertices[prev], vertices[i], vertices[next]) {
+				continue
+			}
+			// drop the tip, and the vertex after it if it lands back on the
+			// vertex before
+			merge := vertices[prev] == vertices[next]
+			vertices = append(vertices[:i], vertices[i+1:]...)
+			indices = append(indices[:i], indices[i+1:]...)
+			if merge {
+				next = i % len(vertices)
+				vertices = append(vertices[:next], vertices[next+1:]...)
+				indices = append(indices[:next], indices[next+1:]...)
+			}
+			changed = true
+		}
+	}
+	return append(vertices, vertices[0]), indices
+}
@@ -0,0 +1,63 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemoveSpikes(t *testing.T) {
+	square := Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}
+
+	// a needle out of the middle of the right edge, and one drawn with an
+	// extra vertex along it out of the top left corner
+	spiky := Polygon{Ring{
+		Coord{0, 0}, Coord{4, 0}, Coord{4, 2}, Coord{100, 2}, Coord{4, 2}, Coord{4, 4},
+		Coord{0, 4}, Coord{-5, 9}, Coord{-10, 14}, Coord{-5, 9}, Coord{0, 4}, Coord{0, 0},
+	}}
+	tips := FindSpikes(spiky)
+	if expected := []Coord{{100, 2}, {-10, 14}}; !reflect.DeepEqual(tips, expected) {
+		t.Errorf("Received %v, expected %v", tips, expected)
+	}
+
+	pruned := RemoveSpikes(spiky)
+	expected := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 2}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+	if !reflect.DeepEqual(pruned, expected) {
+		t.Errorf("Received %v, expected %v", pruned, expected)
+	}
+	AssertEqual(t, len(FindSpikes(pruned)), 0)
+	AssertEqual(t, len(spiky[0]), 12)
+
+	// a spike across the start of the ring
+	seam := Polygon{Ring{Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}, Coord{-3, 0}, Coord{4, 0}}}
+	pruned = RemoveSpikes(seam)
+	AssertEqual(t, len(FindSpikes(pruned)), 0)
+	AssertEqual(t, ringArea(pruned[0]), ringArea(square))
+
+	// the label is the same with less of the bounding box to search
+	result, stats := PolylabelStats(spiky, 0.01, WithSpikePruning(true))
+	expectedResult, expectedStats := PolylabelStats(Polygon{square}, 0.01)
+	if result.X != expectedResult.X || result.Y != expectedResult.Y {
+		t.Errorf("Received %v, expected %v", result, expectedResult)
+	}
+	_, spikyStats := PolylabelStats(spiky, 0.01)
+	if stats.PeakQueue > spikyStats.PeakQueue || stats.PeakQueue != expectedStats.PeakQueue {
+		t.Errorf("Received %+v, expected %+v", stats, expectedStats)
+	}
+}
+
+func TestWithSpikePruningEdgeWeights(t *testing.T) {
+	spiky := Polygon{Ring{
+		Coord{0, 0}, Coord{4, 0}, Coord{4, 2}, Coord{100, 2}, Coord{4, 2}, Coord{4, 4},
+		Coord{0, 4}, Coord{-5, 9}, Coord{-10, 14}, Coord{-5, 9}, Coord{0, 4}, Coord{0, 0},
+	}}
+	pruned := Polygon{Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 2}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}}
+
+	// the soft left edge and the needle out of its top become one soft edge,
+	// and the hard needle out of the right edge hardens the edge it joins
+	weights := [][]float64{{1, 1, 0.5, 0.5, 1, 1, 2, 2, 2, 2, 2}}
+	result := PolylabelVerbose(spiky, 0.01, WithSpikePruning(true), WithEdgeWeights(weights))
+	expected := PolylabelVerbose(pruned, 0.01, WithEdgeWeights([][]float64{{1, 1, 0.5, 1, 2}}))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+}
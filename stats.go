@@ -0,0 +1,31 @@
+package polylabel
+
+// Stats describes the work done by a search, for capacity planning.
+type Stats struct {
+	// PeakQueue is the largest number of cells waiting in the queue at once,
+	// which drives the memory used by the search.
+	PeakQueue int
+	// Subdivisions is the number of cells split into four.
+	Subdivisions int
+	// Discarded is the number of cells dropped to keep within the limit set
+	// by WithMaxQueueSize. If it is not zero the result may not be within
+	// precision of the best achievable.
+	Discarded int
+}
+
+// PolylabelStats is like PolylabelVerbose but also reports the work done by
+// the search.
+func PolylabelStats(polygon Polygon, precision float64, opts ...Option) (Result, Stats) {
+	search := NewSearch(polygon, precision, opts...)
+	search.Run(0)
+	return search.Result(), search.Stats()
+}
+
+// Stats returns the work done by the search so far. Counting starts again
+// from the restored queue for a search resumed with RestoreSearch.
+func (s *Search) Stats() Stats {
+	if s.cells == nil {
+		return Stats{}
+	}
+	return s.cells.stats
+}
@@ -0,0 +1,68 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelStats(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	result, stats := PolylabelStats(polygon, 1.0)
+	if expected := PolylabelVerbose(polygon, 1.0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+	if stats.Subdivisions == 0 || stats.PeakQueue == 0 {
+		t.Errorf("Received %+v, expected work to be counted", stats)
+	}
+
+	// a coarser search does less work
+	_, coarse := PolylabelStats(polygon, 50.0)
+	if coarse.Subdivisions >= stats.Subdivisions || coarse.PeakQueue > stats.PeakQueue {
+		t.Errorf("Received %+v, expected less work than %+v", coarse, stats)
+	}
+
+	// the peak is at least the initial grid and every split adds three cells
+	search := NewSearch(polygon, 1.0)
+	initial := search.Stats()
+	AssertEqual(t, initial.PeakQueue, len(search.cells.queue))
+	AssertEqual(t, initial.Subdivisions, 0)
+	search.Run(10)
+	AssertEqual(t, search.Stats().Subdivisions, 10)
+	AssertEqual(t, search.Stats().PeakQueue, initial.PeakQueue+30)
+
+	_, stats = PolylabelStats(Polygon{Ring{Coord{1, 1}}}, 1.0)
+	AssertEqual(t, stats, Stats{})
+}
+
+func TestWithMaxQueueSize(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	expected, unbounded := PolylabelStats(polygon, 0.01)
+	AssertEqual(t, unbounded.Discarded, 0)
+
+	result, stats := PolylabelStats(polygon, 0.01, WithMaxQueueSize(64))
+	if stats.PeakQueue > 64 || stats.Discarded == 0 {
+		t.Errorf("Received %+v, expected a peak of at most 64 and discarded cells", stats)
+	}
+	if result.Distance > expected.Distance || result.Distance < expected.Distance*0.9 {
+		t.Errorf("Received %v, expected close to %v", result.Distance, expected.Distance)
+	}
+
+	// a limit above the peak changes nothing
+	result, stats = PolylabelStats(polygon, 0.01, WithMaxQueueSize(unbounded.PeakQueue))
+	if !reflect.DeepEqual(result, expected) || !reflect.DeepEqual(stats, unbounded) {
+		t.Errorf("Received %v %+v, expected %v %+v", result, stats, expected, unbounded)
+	}
+}
+
+func TestCellQueueTruncate(t *testing.T) {
+	var q cellQueue
+	for i := 0; i < 10; i++ {
+		q.push(newCell(float64(i), 0, 0, float64(i)), false)
+	}
+	AssertEqual(t, q.truncate(10, false), 0)
+	AssertEqual(t, q.truncate(8, false), 4)
+	for _, expected := range []float64{9, 8, 7, 6, 5, 4} {
+		AssertEqual(t, q.pop(false).d, expected)
+	}
+	AssertEqual(t, len(q), 0)
+}
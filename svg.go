@@ -0,0 +1,398 @@
+package polylabel
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ParseSVGPath parses the d attribute of an SVG path element into a Polygon,
+// with one ring per subpath, such as "M0 0 H4 V4 H0 Z". Absolute and relative
+// M, L, H, V and Z commands are supported, and curve commands are an error.
+// Every subpath is closed, as SVG does when filling a path. Note that the SVG
+// y axis points down, which reverses the winding of every ring.
+func ParseSVGPath(d string) (Polygon, error) {
+	return parseSVGPath(d, false, 0)
+}
+
+// ParseSVGPathCurves is like ParseSVGPath but also accepts the C, S, Q, T and
+// A curve commands, replacing each curve with straight segments that stray
+// from it by at most tolerance. If tolerance is not positive, arcs are split
+// into a segment per degree and other curves into 64 segments.
+func ParseSVGPathCurves(d string, tolerance float64) (Polygon, error) {
+	return parseSVGPath(d, true, tolerance)
+}
+
+func parseSVGPath(d string, curves bool, tolerance float64) (Polygon, error) {
+	p := &svgParser{s: d, curves: curves, tolerance: tolerance}
+	var command byte
+	for {
+		p.skipSeparators()
+		if p.pos == len(p.s) {
+			break
+		}
+		if c := p.s[p.pos]; isSVGCommand(c) {
+			command = c
+			p.pos++
+		} else if command == 0 {
+			return nil, p.errorf("expected a command, found %q", c)
+		} else if command == 'Z' || command == 'z' {
+			return nil, p.errorf("unexpected %q after closepath", c)
+		}
+		if err := p.command(command); err != nil {
+			return nil, err
+		}
+
+		// coordinates following a moveto are implicit linetos
+		if command == 'M' {
+			command = 'L'
+		} else if command == 'm' {
+			command = 'l'
+		}
+	}
+	p.closeRing()
+	if len(p.polygon) == 0 {
+		return nil, p.errorf("no subpaths")
+	}
+	return p.polygon, nil
+}
+
+type svgParser struct {
+	s         string
+	pos       int
+	curves    bool
+	tolerance float64
+
+	polygon  Polygon
+	ring     Ring  // the open subpath, if any
+	start    Coord // first point of the current subpath
+	current  Coord
+	control  Coord // last control point, reflected by S and T
+	previous byte  // previous command, in upper case
+}
+
+func (p *svgParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("polylabel: invalid SVG path at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func isSVGCommand(c byte) bool {
+	switch c &^ 0x20 {
+	case 'M', 'L', 'H', 'V', 'Z', 'C', 'S', 'Q', 'T', 'A':
+		return true
+	}
+	return false
+}
+
+// skip whitespace and at most one comma
+func (p *svgParser) skipSeparators() {
+	comma := false
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+		case c == ',' && !comma:
+			comma = true
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// run a single command and its arguments
+func (p *svgParser) command(command byte) error {
+	var origin Coord
+	if command >= 'a' {
+		origin = p.current
+	}
+	upper := command &^ 0x20
+	switch upper {
+	case 'M':
+		pt, err := p.point(origin)
+		if err != nil {
+			return err
+		}
+		p.closeRing()
+		p.start, p.current = pt, pt
+		p.ring = Ring{pt}
+	case 'L':
+		pt, err := p.point(origin)
+		if err != nil {
+			return err
+		}
+		p.lineTo(pt)
+	case 'H':
+		x, err := p.number()
+		if err != nil {
+			return err
+		}
+		p.lineTo(Coord{origin[0] + x, p.current[1]})
+	case 'V':
+		y, err := p.number()
+		if err != nil {
+			return err
+		}
+		p.lineTo(Coord{p.current[0], origin[1] + y})
+	case 'Z':
+		p.closeRing()
+		p.current = p.start
+	default:
+		if !p.curves {
+			return p.errorf("unsupported curve command %q", command)
+		}
+		if err := p.curve(upper, origin); err != nil {
+			return err
+		}
+	}
+	if upper != 'C' && upper != 'S' && upper != 'Q' && upper != 'T' {
+		p.control = p.current
+	}
+	p.previous = upper
+	return nil
+}
+
+// run a curve command, given in upper case
+func (p *svgParser) curve(command byte, origin Coord) error {
+	// the first control point of a smooth curve mirrors the last one
+	reflected := p.current
+	if (command == 'S' && (p.previous == 'C' || p.previous == 'S')) ||
+		(command == 'T' && (p.previous == 'Q' || p.previous == 'T')) {
+		reflected = Coord{2*p.current[0] - p.control[0], 2*p.current[1] - p.control[1]}
+	}
+
+	var points []Coord
+	switch command {
+	case 'C', 'S', 'Q':
+		n := 2
+		if command == 'C' {
+			n = 3
+		}
+		for i := 0; i < n; i++ {
+			pt, err := p.point(origin)
+			if err != nil {
+				return err
+			}
+			points = append(points, pt)
+		}
+		if command == 'S' {
+			points = append([]Coord{reflected}, points...)
+		}
+	case 'T':
+		pt, err := p.point(origin)
+		if err != nil {
+			return err
+		}
+		points = []Coord{reflected, pt}
+	case 'A':
+		return p.arc(origin)
+	}
+
+	controls := append([]Coord{p.current}, points...)
+	p.control = controls[len(controls)-2]
+	p.bezierTo(controls)
+	return nil
+}
+
+// flatten a quadratic or cubic Bézier curve from its control points
+func (p *svgParser) bezierTo(controls []Coord) {
+	// the distance between a Bézier curve of degree n and n uniform segments
+	// is bounded by n(n-1)/8 times the largest second difference of its
+	// control points (Wang's formula)
+	degree := float64(len(controls) - 1)
+	var second float64
+	for i := 0; i+2 < len(controls); i++ {
+		dx := controls[i][0] - 2*controls[i+1][0] + controls[i+2][0]
+		dy := controls[i][1] - 2*controls[i+1][1] + controls[i+2][1]
+		second = math.Max(second, math.Hypot(dx, dy))
+	}
+	segments := 64
+	if p.tolerance > 0 {
+		n := math.Ceil(math.Sqrt(degree * (degree - 1) / 8 * second / p.tolerance))
+		segments = int(math.Max(1, math.Min(n, maxCurveSegments)))
+	}
+
+	for i := 1; i < segments; i++ {
+		p.lineTo(bezierPoint(controls, float64(i)/float64(segments)))
+	}
+	p.lineTo(controls[len(controls)-1])
+}
+
+// get the point at t along a Bézier curve by de Casteljau's algorithm
+func bezierPoint(controls []Coord, t float64) Coord {
+	points := append([]Coord(nil), controls...)
+	for n := len(points) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			points[i] = Coord{
+				points[i][0] + t*(points[i+1][0]-points[i][0]),
+				points[i][1] + t*(points[i+1][1]-points[i][1]),
+			}
+		}
+	}
+	return points[0]
+}
+
+// flatten an elliptical arc command, following the endpoint to center
+// conversion in the SVG implementation notes
+func (p *svgParser) arc(origin Coord) error {
+	var values [3]float64
+	for i := range values {
+		v, err := p.number()
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	largeArc, err := p.flag()
+	if err != nil {
+		return err
+	}
+	sweep, err := p.flag()
+	if err != nil {
+		return err
+	}
+	end, err := p.point(origin)
+	if err != nil {
+		return err
+	}
+
+	start := p.current
+	rx, ry := math.Abs(values[0]), math.Abs(values[1])
+	if start == end {
+		return nil
+	}
+	if rx == 0 || ry == 0 {
+		p.lineTo(end)
+		return nil
+	}
+
+	sinPhi, cosPhi := math.Sincos(values[2] * math.Pi / 180)
+	mx, my := (start[0]-end[0])/2, (start[1]-end[1])/2
+	x1 := cosPhi*mx + sinPhi*my
+	y1 := -sinPhi*mx + cosPhi*my
+
+	// scale up radii that are too small to reach the end point
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+
+	numerator := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, numerator/(rx*rx*y1*y1+ry*ry*x1*x1)))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (start[0]+end[0])/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (start[1]+end[1])/2
+
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	segments := arcSegments(math.Max(rx, ry), delta, p.tolerance)
+	for i := 1; i < segments; i++ {
+		sin, cos := math.Sincos(theta + delta*float64(i)/float64(segments))
+		p.lineTo(Coord{
+			cx + rx*cos*cosPhi - ry*sin*sinPhi,
+			cy + rx*cos*sinPhi + ry*sin*cosPhi,
+		})
+	}
+	p.lineTo(end)
+	return nil
+}
+
+// add a point to the subpath, starting a new one at the current point after
+// a closepath
+func (p *svgParser) lineTo(pt Coord) {
+	if p.ring == nil {
+		p.ring = Ring{p.current}
+	}
+	p.ring = append(p.ring, pt)
+	p.current = pt
+}
+
+// close the open subpath and add it to the polygon, dropping a lone moveto
+func (p *svgParser) closeRing() {
+	if len(p.ring) > 1 {
+		if p.ring[len(p.ring)-1] != p.ring[0] {
+			p.ring = append(p.ring, p.ring[0])
+		}
+		p.polygon = append(p.polygon, p.ring)
+	}
+	p.ring = nil
+}
+
+func (p *svgParser) point(origin Coord) (Coord, error) {
+	x, err := p.number()
+	if err != nil {
+		return Coord{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return Coord{}, err
+	}
+	return Coord{origin[0] + x, origin[1] + y}, nil
+}
+
+// read a number, which may run straight on from the previous one as in
+// "1-2" or "0.5.5"
+func (p *svgParser) number() (float64, error) {
+	p.skipSeparators()
+	start := p.pos
+	if p.pos < len(p.s) && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+		p.pos++
+	}
+	digits := p.digits()
+	if p.pos < len(p.s) && p.s[p.pos] == '.' {
+		p.pos++
+		digits += p.digits()
+	}
+	if digits == 0 {
+		p.pos = start
+		if p.pos == len(p.s) {
+			return 0, p.errorf("expected a number, found end of input")
+		}
+		return 0, p.errorf("expected a number, found %q", p.s[p.pos])
+	}
+	if p.pos < len(p.s) && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+		mantissa := p.pos
+		p.pos++
+		if p.pos < len(p.s) && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+			p.pos++
+		}
+		if p.digits() == 0 {
+			p.pos = mantissa
+		}
+	}
+	text := p.s[start:p.pos]
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid number %q", text)
+	}
+	return v, nil
+}
+
+// skip a run of digits, returning how many there were
+func (p *svgParser) digits() int {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.pos - start
+}
+
+// read an arc flag, which is a single 0 or 1 and need not be separated from
+// what follows
+func (p *svgParser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.pos < len(p.s) && (p.s[p.pos] == '0' || p.s[p.pos] == '1') {
+		p.pos++
+		return p.s[p.pos-1] == '1', nil
+	}
+	return false, p.errorf("expected an arc flag")
+}
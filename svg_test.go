@@ -0,0 +1,123 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseSVGPath(t *testing.T) {
+	square := Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}}
+	for _, d := range []string{
+		"M0 0 L4 0 L4 4 L0 4 Z",
+		"M0,0 4,0 4,4 0,4z",
+		"m0 0 h4 v4 h-4 z",
+		"M 0 0 H 4 V 4 H 0",
+		"M0-0l4 0 0 4-4 0",
+	} {
+		polygon, err := ParseSVGPath(d)
+		if err != nil {
+			t.Fatalf("%q: %v", d, err)
+		}
+		if expected := (Polygon{square}); !reflect.DeepEqual(polygon, expected) {
+			t.Errorf("%q: Received %v, expected %v", d, polygon, expected)
+		}
+	}
+
+	// a relative moveto after a closepath starts from the closed subpath
+	polygon, err := ParseSVGPath("M0 0h10v10h-10z m2 2 h2 v2 h-2z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Polygon{
+		Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}},
+		Ring{Coord{2, 2}, Coord{4, 2}, Coord{4, 4}, Coord{2, 4}, Coord{2, 2}},
+	}
+	if !reflect.DeepEqual(polygon, expected) {
+		t.Errorf("Received %v, expected %v", polygon, expected)
+	}
+
+	// numbers may run into each other
+	polygon, err = ParseSVGPath("M.5.5L1e1.5 10 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Polygon{Ring{Coord{0.5, 0.5}, Coord{10, 0.5}, Coord{10, 10}, Coord{0.5, 0.5}}}
+	if !reflect.DeepEqual(polygon, expected) {
+		t.Errorf("Received %v, expected %v", polygon, expected)
+	}
+}
+
+func TestParseSVGPathErrors(t *testing.T) {
+	for _, d := range []string{
+		"",
+		"M0 0",
+		"0 0 L1 1",
+		"M0 0 L1",
+		"M0 0 L1 x",
+		"M0 0 L1 0 L1 1 Z 3",
+		"M0 0 C1 1 2 2 3 3",
+		"M0 0 X1 1",
+	} {
+		if _, err := ParseSVGPath(d); err == nil {
+			t.Errorf("Expected an error for %q", d)
+		}
+	}
+	if _, err := ParseSVGPathCurves("M0 0 A1 1 0 2 0 1 1", 0.1); err == nil {
+		t.Error("Expected an error for an invalid arc flag")
+	}
+}
+
+func TestParseSVGPathCurves(t *testing.T) {
+	tolerance := 0.001
+	for _, d := range []string{
+		// two semicircular arcs, with the flags run together
+		"M1 0 A1 1 0 0 1 -1 0 A1 1 0 0 1 1 0 Z",
+		"M1 0a1 1 0 01-2 0a1 1 0 012 0z",
+		// radii that are too small are scaled up to reach the end point
+		"M1 0 A0.5 0.5 0 0 1 -1 0 A0.5 0.5 0 0 1 1 0 Z",
+	} {
+		polygon, err := ParseSVGPathCurves(d, tolerance)
+		if err != nil {
+			t.Fatalf("%q: %v", d, err)
+		}
+		for _, p := range polygon[0] {
+			if r := math.Hypot(p[0], p[1]); math.Abs(r-1) > 1e-9 {
+				t.Errorf("%q: Received %v, expected a point on the unit circle", d, p)
+			}
+		}
+		if len(polygon[0]) < 10 {
+			t.Errorf("%q: Received %d points, expected the arcs to be flattened", d, len(polygon[0]))
+		}
+	}
+
+	// a cubic and its smooth continuation stay within tolerance of the curve
+	polygon, err := ParseSVGPathCurves("M0 0 C0 10 10 10 10 0 S20 -10 20 0 Z", tolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := polygon[0]
+	AssertEqual(t, ring[0], Coord{0, 0})
+	AssertEqual(t, ring[len(ring)-1], Coord{0, 0})
+	for _, u := range []float64{0.1, 0.37, 0.5, 0.9} {
+		pt := bezierPoint([]Coord{{0, 0}, {0, 10}, {10, 10}, {10, 0}}, u)
+		if d := math.Abs(pointToPolygonDistance(pt[0], pt[1], polygon)); d > tolerance {
+			t.Errorf("Received %v from the curve at %v, expected at most %v", d, u, tolerance)
+		}
+		// the smooth curve mirrors the first control point
+		pt = bezierPoint([]Coord{{10, 0}, {10, -10}, {20, -10}, {20, 0}}, u)
+		if d := math.Abs(pointToPolygonDistance(pt[0], pt[1], polygon)); d > tolerance {
+			t.Errorf("Received %v from the smooth curve at %v, expected at most %v", d, u, tolerance)
+		}
+	}
+
+	// quadratic curves with a smooth continuation
+	polygon, err = ParseSVGPathCurves("M0 0 Q5 10 10 0 T20 0 Z", tolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt := bezierPoint([]Coord{{10, 0}, {15, -10}, {20, 0}}, 0.5)
+	if d := math.Abs(pointToPolygonDistance(pt[0], pt[1], polygon)); d > tolerance {
+		t.Errorf("Received %v from the smooth curve, expected at most %v", d, tolerance)
+	}
+}
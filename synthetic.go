@@ -0,0 +1,23 @@
+package polylabel
+
+import "math"
+
+// SyntheticPolygon returns a deterministic polygon whose exterior has the
+// given number of distinct vertices, for use in benchmarks and tests. The
+// exterior is a wavy circle of radius 100 centred on the origin and contains
+// a square hole of 4 more vertices, so the pole does not sit at the
+// centroid. Fewer than 3 vertices are treated as 3.
+func SyntheticPolygon(vertices int) Polygon {
+	if vertices < 3 {
+		vertices = 3
+	}
+	exterior := make(Ring, vertices+1)
+	for i := 0; i < vertices; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(vertices)
+		radius := 100 * (1 + 0.2*math.Sin(7*angle))
+		exterior[i] = Coord{radius * math.Cos(angle), radius * math.Sin(angle)}
+	}
+	exterior[vertices] = exterior[0]
+	hole := Ring{Coord{-10, -30}, Coord{-10, 10}, Coord{30, 10}, Coord{30, -30}, Coord{-10, -30}}
+	return Polygon{exterior, hole}
+}
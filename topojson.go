@@ -0,0 +1,133 @@
+package polylabel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FromTopoJSON decodes the polygons of the named object of a TopoJSON
+// topology. Polygon and MultiPolygon geometries give one Polygon for each of
+// their polygons, in order, and are found inside any GeometryCollection;
+// other geometries are skipped. The rings are stitched together from the
+// shared arcs, and quantized arcs are decoded with the transform of the
+// topology.
+func FromTopoJSON(data []byte, objectName string) ([]Polygon, error) {
+	var topology struct {
+		Type      string
+		Transform *struct {
+			Scale     [2]float64
+			Translate [2]float64
+		}
+		Objects map[string]*topoGeometry
+		Arcs    [][][]float64
+	}
+	if err := json.Unmarshal(data, &topology); err != nil {
+		return nil, fmt.Errorf("polylabel: invalid TopoJSON: %v", err)
+	}
+	if topology.Type != "Topology" {
+		return nil, errors.New("polylabel: not a TopoJSON topology")
+	}
+	object, ok := topology.Objects[objectName]
+	if !ok || object == nil {
+		return nil, fmt.Errorf("polylabel: no TopoJSON object %q", objectName)
+	}
+
+	// decode the arcs, which are delta encoded if quantized
+	arcs := make([]Ring, len(topology.Arcs))
+	for i, positions := range topology.Arcs {
+		arc := make(Ring, len(positions))
+		var x, y float64
+		for n, position := range positions {
+			if len(position) < 2 {
+				return nil, fmt.Errorf("polylabel: TopoJSON arc %d has a position with %d values", i, len(position))
+			}
+			if t := topology.Transform; t != nil {
+				x += position[0]
+				y += position[1]
+				arc[n] = Coord{x*t.Scale[0] + t.Translate[0], y*t.Scale[1] + t.Translate[1]}
+			} else {
+				arc[n] = Coord{position[0], position[1]}
+			}
+		}
+		arcs[i] = arc
+	}
+
+	var polygons []Polygon
+	if err := object.polygons(arcs, &polygons); err != nil {
+		return nil, err
+	}
+	return polygons, nil
+}
+
+type topoGeometry struct {
+	Type       string
+	Arcs       json.RawMessage
+	Geometries []*topoGeometry
+}
+
+// append the polygons of a geometry
+func (g *topoGeometry) polygons(arcs []Ring, polygons *[]Polygon) error {
+	switch g.Type {
+	case "GeometryCollection":
+		for _, geometry := range g.Geometries {
+			if geometry == nil {
+				continue
+			}
+			if err := geometry.polygons(arcs, polygons); err != nil {
+				return err
+			}
+		}
+	case "Polygon":
+		var rings [][]int
+		if err := json.Unmarshal(g.Arcs, &rings); err != nil {
+			return fmt.Errorf("polylabel: invalid TopoJSON polygon arcs: %v", err)
+		}
+		polygon, err := stitchTopoRings(arcs, rings)
+		if err != nil {
+			return err
+		}
+		*polygons = append(*polygons, polygon)
+	case "MultiPolygon":
+		var parts [][][]int
+		if err := json.Unmarshal(g.Arcs, &parts); err != nil {
+			return fmt.Errorf("polylabel: invalid TopoJSON multipolygon arcs: %v", err)
+		}
+		for _, rings := range parts {
+			polygon, err := stitchTopoRings(arcs, rings)
+			if err != nil {
+				return err
+			}
+			*polygons = append(*polygons, polygon)
+		}
+	}
+	return nil
+}
+
+// join the arcs of each ring end to end, where a negative index ~i is arc i
+// reversed and each arc starts where the previous one ended
+func stitchTopoRings(arcs []Ring, rings [][]int) (Polygon, error) {
+	polygon := make(Polygon, len(rings))
+	for i, indices := range rings {
+		var ring Ring
+		for _, index := range indices {
+			reversed := index < 0
+			if reversed {
+				index = ^index
+			}
+			if index >= len(arcs) {
+				return nil, fmt.Errorf("polylabel: TopoJSON arc index %d out of range for %d arcs", index, len(arcs))
+			}
+			arc := arcs[index]
+			if reversed {
+				arc = reverseRing(arc)
+			}
+			if len(ring) > 0 && len(arc) > 0 {
+				arc = arc[1:]
+			}
+			ring = append(ring, arc...)
+		}
+		polygon[i] = ring
+	}
+	return polygon, nil
+}
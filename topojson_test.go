@@ -0,0 +1,77 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromTopoJSON(t *testing.T) {
+	// two unit squares sharing the arc x = 1, and a point
+	data := []byte(`{
+		"type": "Topology",
+		"objects": {
+			"squares": {
+				"type": "GeometryCollection",
+				"geometries": [
+					{"type": "Polygon", "arcs": [[0, 1]]},
+					{"type": "MultiPolygon", "arcs": [[[2, -1]]]},
+					{"type": "Point", "coordinates": [0, 0]},
+					{"type": null}
+				]
+			}
+		},
+		"arcs": [
+			[[1, 0], [1, 1]],
+			[[1, 1], [0, 1], [0, 0], [1, 0]],
+			[[1, 0], [2, 0], [2, 1], [1, 1]]
+		]
+	}`)
+	polygons, err := FromTopoJSON(data, "squares")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Polygon{
+		{Ring{{1, 0}, {1, 1}, {0, 1}, {0, 0}, {1, 0}}},
+		{Ring{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}}},
+	}
+	if !reflect.DeepEqual(polygons, expected) {
+		t.Errorf("Received %v, expected %v", polygons, expected)
+	}
+
+	// a quantized square with a hole, both delta encoded
+	data = []byte(`{
+		"type": "Topology",
+		"transform": {"scale": [0.5, 2], "translate": [10, 20]},
+		"objects": {"square": {"type": "Polygon", "arcs": [[0], [1]]}},
+		"arcs": [
+			[[0, 0], [8, 0], [0, 4], [-8, 0], [0, -4]],
+			[[2, 1], [0, 2], [4, 0], [0, -2], [-4, 0]]
+		]
+	}`)
+	polygons, err = FromTopoJSON(data, "square")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Polygon{{
+		Ring{{10, 20}, {14, 20}, {14, 28}, {10, 28}, {10, 20}},
+		Ring{{11, 22}, {11, 26}, {13, 26}, {13, 22}, {11, 22}},
+	}}
+	if !reflect.DeepEqual(polygons, expected) {
+		t.Errorf("Received %v, expected %v", polygons, expected)
+	}
+}
+
+func TestFromTopoJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"type": "Topology"`,
+		`{"type": "FeatureCollection", "features": []}`,
+		`{"type": "Topology", "objects": {}, "arcs": []}`,
+		`{"type": "Topology", "objects": {"a": {"type": "Polygon", "arcs": [[0, 1]]}}, "arcs": [[[0, 0], [1, 0]]]}`,
+		`{"type": "Topology", "objects": {"a": {"type": "Polygon", "arcs": [[-1]]}}, "arcs": []}`,
+		`{"type": "Topology", "objects": {"a": {"type": "Polygon", "arcs": [[0]]}}, "arcs": [[[0]]]}`,
+	} {
+		if _, err := FromTopoJSON([]byte(data), "a"); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package polylabel
+
+import "math"
+
+// PolylabelUnionApprox labels the union of polygons, which may overlap,
+// without computing the union. The distance at a point is taken as the
+// greatest of its distances from each polygon, so a point is inside the union
+// if it is inside any of the polygons. This is exact away from the overlaps
+// but underestimates the room where polygons overlap, since the outline of
+// one polygon inside another still counts, so the label favours the interior
+// of a single polygon over a point straddling two. The search covers the
+// bounding box of all the polygons and starts from their centroid, weighted
+// by area. The options are limited as for PolylabelSource.
+func PolylabelUnionApprox(polygons []Polygon, precision float64, opts ...Option) Result {
+	var closed []Polygon
+	var exteriors []Ring
+	for _, polygon := range polygons {
+		if len(polygon) == 0 || len(polygon[0]) == 0 {
+			continue
+		}
+		polygon = closeRings(polygon)
+		closed = append(closed, polygon)
+		exteriors = append(exteriors, polygon[0])
+	}
+	bounds, cx, cy := ringsExtent(exteriors)
+	return labelDistance(bounds, cx, cy, func(x, y float64) float64 {
+		return pointToUnionDistance(x, y, closed)
+	}, precision, opts)
+}
+
+// the greatest signed distance from point to the outline of any of polygons
+func pointToUnionDistance(x float64, y float64, polygons []Polygon) float64 {
+	d := math.Inf(-1)
+	for _, polygon := range polygons {
+		d = math.Max(d, pointToPolygonDistance(x, y, polygon))
+	}
+	return d
+}
@@ -0,0 +1,36 @@
+package polylabel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPolylabelUnionApprox(t *testing.T) {
+	// a single polygon is labelled as usual
+	polygon := loadData("test_data/water1.json")
+	expected := PolylabelVerbose(polygon, 1.0)
+	if result := PolylabelUnionApprox([]Polygon{polygon}, 1.0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Received %v, expected %v", result, expected)
+	}
+
+	// the label goes in the larger of two disjoint squares
+	small := Polygon{Ring{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}
+	large := Polygon{Ring{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}}
+	result := PolylabelUnionApprox([]Polygon{small, large}, 0.01)
+	AssertEqual(t, result.X, 15.0)
+	AssertEqual(t, result.Y, 5.0)
+	AssertEqual(t, result.Distance, 5.0)
+
+	// overlapping squares, the second unclosed
+	first := Polygon{Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}
+	second := Polygon{Ring{{6, -1}, {18, -1}, {18, 11}, {6, 11}}}
+	result = PolylabelUnionApprox([]Polygon{first, second}, 0.01)
+	if math.Abs(result.X-12) > 0.01 || math.Abs(result.Y-5) > 0.01 || result.Distance < 6-0.01 {
+		t.Errorf("Received %v, expected near (12, 5)", result)
+	}
+
+	if result := PolylabelUnionApprox([]Polygon{{}}, 1.0); !reflect.DeepEqual(result, Result{}) {
+		t.Errorf("Received %v for an empty polygon", result)
+	}
+}
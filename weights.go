@@ -0,0 +1,91 @@
+package polylabel
+
+import "math"
+
+// WithEdgeWeights scales the distance to each edge of the polygon by a
+// weight, so that some edges repel the label more than others. weights[i][n]
+// is the weight of the edge from polygon[i][n] to polygon[i][n+1]. Edges
+// without a weight, including all edges of rings beyond len(weights), have a
+// weight of 1.
+//
+// A weight above 1 marks a soft edge, such as a shared border, that the label
+// may come closer to: with a weight of 2 the label may be half as far from it
+// as from an ordinary edge. A weight below 1 marks a hard edge, such as a
+// coastline, that the label keeps further away from. Weights must be
+// positive. The distance reported in the Result is the weighted distance.
+func WithEdgeWeights(weights [][]float64) Option {
+	return func(o *options) {
+		o.edgeWeights = weights
+	}
+}
+
+// the weight of edge n of ring i
+func edgeWeight(weights [][]float64, i int, n int) float64 {
+	if i < len(weights) && n < len(weights[i]) {
+		return weights[i][n]
+	}
+	return 1
+}
+
+// carry edge weights over to a polygon whose rings keep only some of the
+// vertices of the rings of polygon, given by their indices in order. Each
+// edge between kept vertices takes the smallest weight of the edges it
+// replaces, so that a hard edge stays hard.
+func remapEdgeWeights(weights [][]float64, polygon Polygon, kept [][]int) [][]float64 {
+	remapped := make([][]float64, len(weights))
+	for i := range weights {
+		if i >= len(polygon) || i >= len(kept) || len(kept[i]) == 0 {
+			continue
+		}
+		edges := len(polygon[i]) - 1
+		ring := make([]float64, len(kept[i]))
+		for j, a := range kept[i] {
+			b := kept[i][(j+1)%len(kept[i])]
+			w := math.Inf(1)
+			for n := a; ; {
+				w = math.Min(w, edgeWeight(weights, i, n))
+				if n = (n + 1) % edges; n == b {
+					break
+				}
+			}
+			ring[j] = w
+		}
+		remapped[i] = ring
+	}
+	return remapped
+}
+
+// return options whose edge weights are carried over to a polygon reduced to
+// the kept vertices of polygon, as for remapEdgeWeights
+func (o *options) keepEdgeWeights(polygon Polygon, kept [][]int) *options {
+	if o.edgeWeights == nil || kept == nil {
+		return o
+	}
+	copied := *o
+	copied.edgeWeights = remapEdgeWeights(o.edgeWeights, polygon, kept)
+	return &copied
+}
+
+// the largest weight of any edge, counting edges without a weight as 1
+func maxEdgeWeight(weights [][]float64) float64 {
+	max := 1.0
+	for _, ring := range weights {
+		for _, w := range ring {
+			max = math.Max(max, w)
+		}
+	}
+	return max
+}
+
+// smallest distance from a point to an edge of the polygon outline, scaling
+// the distance to each edge by its weight
+func weightedOutlineDistance(x float64, y float64, polygon Polygon, weights [][]float64) float64 {
+	minDist := math.Inf(1)
+	for i, ring := range polygon {
+		for n := 0; n < (len(ring) - 1); n++ {
+			d := math.Sqrt(segmentDistanceSquared(x, y, ring[n], ring[n+1]))
+			minDist = math.Min(minDist, d*edgeWeight(weights, i, n))
+		}
+	}
+	return minDist
+}
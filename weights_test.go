@@ -0,0 +1,32 @@
+package polylabel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWithEdgeWeights(t *testing.T) {
+	// the left edge of the square is hard and repels the label twice as much
+	polygon := Polygon{Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}}}
+	weights := [][]float64{{1, 1, 1, 0.5}}
+
+	result := PolylabelVerbose(polygon, 0.001, WithEdgeWeights(weights))
+	if math.Abs(result.X-20.0/3) > 0.01 || math.Abs(result.Y-5) > 1.7 {
+		t.Errorf("Received %v, %v, expected x = 20/3", result.X, result.Y)
+	}
+	if math.Abs(result.Distance-10.0/3) > 0.001 {
+		t.Errorf("Received distance %v, expected 10/3", result.Distance)
+	}
+
+	// soft edges let the label approach them, so long as the search bound
+	// accounts for the larger weight
+	weights = [][]float64{{1, 1, 1, 3}}
+	result = PolylabelVerbose(polygon, 0.001, WithEdgeWeights(weights))
+	if math.Abs(result.Distance-5) > 0.001 {
+		t.Errorf("Received distance %v, expected 5", result.Distance)
+	}
+
+	// points outside keep a negative distance
+	d := newOptions([]Option{WithEdgeWeights(weights)}).distance(polygon)(-1, 5)
+	AssertEqual(t, d, -3.0)
+}
@@ -0,0 +1,210 @@
+package polylabel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// the WKB geometry type of a point, and the EWKB flag marking an SRID
+const (
+	wkbPoint     = 1
+	ewkbSRIDFlag = 0x20000000
+)
+
+// FormatWKB encodes a point as Well-Known Binary in the given byte order,
+// which must be binary.LittleEndian or binary.BigEndian. If srid is not zero
+// the point is encoded as Extended WKB carrying srid, as PostGIS accepts.
+func FormatWKB(x float64, y float64, order binary.ByteOrder, srid uint32) ([]byte, error) {
+	var flag byte
+	switch order {
+	case binary.LittleEndian:
+		flag = 1
+	case binary.BigEndian:
+		flag = 0
+	default:
+		return nil, errors.New("polylabel: WKB byte order must be little or big endian")
+	}
+	typ := uint32(wkbPoint)
+	b := make([]byte, 5, 25)
+	b[0] = flag
+	if srid != 0 {
+		typ |= ewkbSRIDFlag
+		b = b[:9]
+		order.PutUint32(b[5:], srid)
+	}
+	order.PutUint32(b[1:], typ)
+	n := len(b)
+	b = b[:n+16]
+	order.PutUint64(b[n:], math.Float64bits(x))
+	order.PutUint64(b[n+8:], math.Float64bits(y))
+	return b, nil
+}
+
+// PolylabelWKB labels a polygon and returns the label as a Well-Known Binary
+// point encoded as for FormatWKB, ready to pass to a database as a binary
+// parameter. It is an error if polygon is empty.
+func PolylabelWKB(polygon Polygon, precision float64, order binary.ByteOrder, srid uint32, opts ...Option) ([]byte, error) {
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return nil, errors.New("polylabel: empty polygon")
+	}
+	x, y := Polylabel(polygon, precision, opts...)
+	return FormatWKB(x, y, order, srid)
+}
+
+// ParseWKB decodes a Polygon or MultiPolygon given as Well-Known Binary,
+// returning one Polygon for a Polygon geometry and one for each polygon of a
+// MultiPolygon. Either byte order is accepted, as are Z, M and ZM geometries
+// in ISO or Extended WKB form, whose extra values are dropped, and an SRID
+// in Extended WKB, which is ignored.
+func ParseWKB(data []byte) ([]Polygon, error) {
+	r := &wkbReader{data: data}
+	polygons, err := r.geometry(true)
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.data) {
+		return nil, r.errorf("unexpected bytes after geometry")
+	}
+	return polygons, nil
+}
+
+// the geometry types of WKB polygons and multipolygons, and the flags of
+// Extended WKB
+const (
+	wkbPolygon      = 3
+	wkbMultiPolygon = 6
+	ewkbZFlag       = 0x80000000
+	ewkbMFlag       = 0x40000000
+)
+
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+func (r *wkbReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("polylabel: invalid WKB at offset %d: %s", r.pos, fmt.Sprintf(format, args...))
+}
+
+// read a geometry, which is a polygon or, at the top level, a multipolygon
+func (r *wkbReader) geometry(multi bool) ([]Polygon, error) {
+	if r.pos >= len(r.data) {
+		return nil, r.errorf("expected a byte order")
+	}
+	switch r.data[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return nil, r.errorf("unknown byte order %d", r.data[r.pos])
+	}
+	r.pos++
+	typ, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+
+	dims := 2
+	if typ&ewkbZFlag != 0 {
+		dims++
+	}
+	if typ&ewkbMFlag != 0 {
+		dims++
+	}
+	if typ&ewkbSRIDFlag != 0 {
+		if _, err := r.uint32(); err != nil {
+			return nil, err
+		}
+	}
+	typ &^= ewkbZFlag | ewkbMFlag | ewkbSRIDFlag
+	// ISO WKB adds 1000 for Z, 2000 for M and 3000 for both
+	switch typ / 1000 {
+	case 1, 2:
+		dims++
+	case 3:
+		dims += 2
+	}
+
+	switch typ % 1000 {
+	case wkbPolygon:
+		polygon, err := r.polygon(dims)
+		if err != nil {
+			return nil, err
+		}
+		return []Polygon{polygon}, nil
+	case wkbMultiPolygon:
+		if !multi {
+			break
+		}
+		n, err := r.count()
+		if err != nil {
+			return nil, err
+		}
+		var polygons []Polygon
+		for i := 0; i < n; i++ {
+			polygon, err := r.geometry(false)
+			if err != nil {
+				return nil, err
+			}
+			polygons = append(polygons, polygon...)
+		}
+		return polygons, nil
+	}
+	return nil, r.errorf("unsupported geometry type %d", typ)
+}
+
+// read the rings of a polygon whose coordinates have dims values
+func (r *wkbReader) polygon(dims int) (Polygon, error) {
+	rings, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	polygon := make(Polygon, rings)
+	for i := range polygon {
+		n, err := r.count()
+		if err != nil {
+			return nil, err
+		}
+		if n > (len(r.data)-r.pos)/(8*dims) {
+			return nil, r.errorf("ring of %d coordinates overruns the data", n)
+		}
+		ring := make(Ring, n)
+		for k := range ring {
+			for d := 0; d < dims; d++ {
+				v := math.Float64frombits(r.order.Uint64(r.data[r.pos:]))
+				if d < 2 {
+					ring[k][d] = v
+				}
+				r.pos += 8
+			}
+		}
+		polygon[i] = ring
+	}
+	return polygon, nil
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.data)-r.pos < 4 {
+		return 0, r.errorf("unexpected end of data")
+	}
+	v := r.order.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// read a count of rings, points or polygons
+func (r *wkbReader) count() (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	// each element takes at least 4 bytes, so larger counts are corrupt
+	if int64(n) > int64(len(r.data)-r.pos)/4 {
+		return 0, r.errorf("count %d overruns the data", n)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,123 @@
+package polylabel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestFormatWKB(t *testing.T) {
+	for _, test := range []struct {
+		order    binary.ByteOrder
+		srid     uint32
+		expected string
+	}{
+		{binary.LittleEndian, 0, "0101000000000000000000f03f0000000000000040"},
+		{binary.BigEndian, 0, "00000000013ff00000000000004000000000000000"},
+		{binary.LittleEndian, 4326, "0101000020e6100000000000000000f03f0000000000000040"},
+		{binary.BigEndian, 4326, "0020000001000010e63ff00000000000004000000000000000"},
+	} {
+		b, err := FormatWKB(1, 2, test.order, test.srid)
+		if err != nil || hex.EncodeToString(b) != test.expected {
+			t.Errorf("Received %x, %v, expected %s", b, err, test.expected)
+		}
+	}
+	if _, err := FormatWKB(1, 2, nil, 0); err == nil {
+		t.Errorf("Expected an error for a nil byte order")
+	}
+}
+
+func TestPolylabelWKB(t *testing.T) {
+	polygon := loadData("test_data/water1.json")
+	x, y := Polylabel(polygon, 1.0)
+	expected, _ := FormatWKB(x, y, binary.LittleEndian, 3857)
+	b, err := PolylabelWKB(polygon, 1.0, binary.LittleEndian, 3857)
+	if err != nil || hex.EncodeToString(b) != hex.EncodeToString(expected) {
+		t.Errorf("Received %x, %v, expected %x", b, err, expected)
+	}
+
+	if _, err := PolylabelWKB(Polygon{}, 1.0, binary.LittleEndian, 0); err == nil {
+		t.Errorf("Expected an error for an empty polygon")
+	}
+}
+
+// encode a polygon as WKB of the given type, adding extra values to each
+// coordinate
+func appendWKBPolygon(b *bytes.Buffer, order binary.ByteOrder, typ uint32, extra int, polygon Polygon) {
+	if order == binary.LittleEndian {
+		b.WriteByte(1)
+	} else {
+		b.WriteByte(0)
+	}
+	binary.Write(b, order, typ)
+	binary.Write(b, order, uint32(len(polygon)))
+	for _, ring := range polygon {
+		binary.Write(b, order, uint32(len(ring)))
+		for _, c := range ring {
+			binary.Write(b, order, c)
+			for i := 0; i < extra; i++ {
+				binary.Write(b, order, float64(99))
+			}
+		}
+	}
+}
+
+func TestParseWKB(t *testing.T) {
+	square := Polygon{
+		Ring{Coord{0, 0}, Coord{4, 0}, Coord{4, 4}, Coord{0, 4}, Coord{0, 0}},
+		Ring{Coord{1, 1}, Coord{1, 2}, Coord{2, 2}, Coord{2, 1}, Coord{1, 1}},
+	}
+	triangle := Polygon{Ring{Coord{10, 0}, Coord{16, 0}, Coord{10, 6}, Coord{10, 0}}}
+
+	for _, test := range []struct {
+		typ   uint32
+		extra int
+	}{
+		{3, 0}, {1003, 1}, {2003, 1}, {3003, 2},
+		{3 | ewkbZFlag, 1}, {3 | ewkbZFlag | ewkbMFlag, 2},
+	} {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			var b bytes.Buffer
+			appendWKBPolygon(&b, order, test.typ, test.extra, square)
+			polygons, err := ParseWKB(b.Bytes())
+			if err != nil || !reflect.DeepEqual(polygons, []Polygon{square}) {
+				t.Errorf("Received %v, %v for type %d, expected %v", polygons, err, test.typ, square)
+			}
+		}
+	}
+
+	// a multipolygon with an SRID, whose parts may differ in byte order
+	var b bytes.Buffer
+	b.WriteByte(1)
+	binary.Write(&b, binary.LittleEndian, uint32(6|ewkbSRIDFlag))
+	binary.Write(&b, binary.LittleEndian, uint32(4326))
+	binary.Write(&b, binary.LittleEndian, uint32(2))
+	appendWKBPolygon(&b, binary.BigEndian, 3, 0, square)
+	appendWKBPolygon(&b, binary.LittleEndian, 3, 0, triangle)
+	polygons, err := ParseWKB(b.Bytes())
+	if err != nil || !reflect.DeepEqual(polygons, []Polygon{square, triangle}) {
+		t.Errorf("Received %v, %v, expected the square and the triangle", polygons, err)
+	}
+
+	point, _ := FormatWKB(1, 2, binary.LittleEndian, 0)
+	b.Reset()
+	appendWKBPolygon(&b, binary.LittleEndian, 3, 0, square)
+	valid := b.Bytes()
+	for _, test := range []struct {
+		data     []byte
+		expected string
+	}{
+		{nil, "polylabel: invalid WKB at offset 0: expected a byte order"},
+		{[]byte{2}, "polylabel: invalid WKB at offset 0: unknown byte order 2"},
+		{point, "polylabel: invalid WKB at offset 5: unsupported geometry type 1"},
+		{valid[:len(valid)-1], "polylabel: invalid WKB at offset 97: ring of 5 coordinates overruns the data"},
+		{append(valid[:len(valid):len(valid)], 0), "polylabel: invalid WKB at offset 177: unexpected bytes after geometry"},
+		{[]byte{1, 3, 0, 0, 0, 255, 255, 255, 255}, "polylabel: invalid WKB at offset 9: count 4294967295 overruns the data"},
+	} {
+		if _, err := ParseWKB(test.data); err == nil || err.Error() != test.expected {
+			t.Errorf("Received %v, expected %v", err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package polylabel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseWKT parses a POLYGON in Well-Known Text, such as
+// "POLYGON((0 0, 4 0, 4 4, 0 4, 0 0))". Z and M values are accepted and
+// dropped.
+func ParseWKT(wkt string) (Polygon, error) {
+	p := &wktParser{s: wkt}
+	tag := strings.ToUpper(p.word())
+	if tag != "POLYGON" {
+		return nil, p.errorf("expected POLYGON, found %q", tag)
+	}
+	switch dims := strings.ToUpper(p.word()); dims {
+	case "", "Z", "M", "ZM":
+	case "EMPTY":
+		return nil, p.errorf("empty polygon")
+	default:
+		return nil, p.errorf("unexpected %q", dims)
+	}
+
+	var polygon Polygon
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	for {
+		ring, err := p.ring()
+		if err != nil {
+			return nil, err
+		}
+		polygon = append(polygon, ring)
+		if !p.accept(',') {
+			break
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected text after polygon")
+	}
+	return polygon, nil
+}
+
+// FormatWKT formats a point as Well-Known Text, such as "POINT(2 2)".
+func FormatWKT(x float64, y float64) string {
+	return "POINT(" + strconv.FormatFloat(x, 'f', -1, 64) + " " + strconv.FormatFloat(y, 'f', -1, 64) + ")"
+}
+
+// PolylabelString labels a polygon given in Well-Known Text and returns the
+// label as a Well-Known Text point, for quick use from scripts.
+func PolylabelString(wkt string, precision float64, opts ...Option) (string, error) {
+	polygon, err := ParseWKT(wkt)
+	if err != nil {
+		return "", err
+	}
+	return FormatWKT(Polylabel(polygon, precision, opts...)), nil
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("polylabel: invalid WKT at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// read a run of letters
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && unicode.IsLetter(rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// consume c if it is the next character
+func (p *wktParser) accept(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.accept(c) {
+		if p.pos == len(p.s) {
+			return p.errorf("expected %q, found end of input", c)
+		}
+		return p.errorf("expected %q, found %q", c, p.s[p.pos])
+	}
+	return nil
+}
+
+func (p *wktParser) number() (float64, bool, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, false, nil
+	}
+	text := p.s[start:p.pos]
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.pos = start
+		return 0, false, p.errorf("invalid number %q", text)
+	}
+	return v, true, nil
+}
+
+func (p *wktParser) ring() (Ring, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var ring Ring
+	for {
+		var values []float64
+		for {
+			v, ok, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			values = append(values, v)
+		}
+		if len(values) < 2 || len(values) > 4 {
+			return nil, p.errorf("expected 2 to 4 values in a coordinate, found %d", len(values))
+		}
+		ring = append(ring, Coord{values[0], values[1]})
+		if !p.accept(',') {
+			break
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
@@ -0,0 +1,57 @@
+package polylabel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWKT(t *testing.T) {
+	polygon, err := ParseWKT("polygon ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 2 4, 4 4, 4 2, 2 2))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Polygon{
+		Ring{Coord{0, 0}, Coord{10, 0}, Coord{10, 10}, Coord{0, 10}, Coord{0, 0}},
+		Ring{Coord{2, 2}, Coord{2, 4}, Coord{4, 4}, Coord{4, 2}, Coord{2, 2}},
+	}
+	if !reflect.DeepEqual(polygon, expected) {
+		t.Errorf("Received %v, expected %v", polygon, expected)
+	}
+
+	polygon, err = ParseWKT("POLYGON Z((0 0 1,1 0 1,1 1 1,0 0 1))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Polygon{Ring{Coord{0, 0}, Coord{1, 0}, Coord{1, 1}, Coord{0, 0}}}
+	if !reflect.DeepEqual(polygon, expected) {
+		t.Errorf("Received %v, expected %v", polygon, expected)
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	for _, wkt := range []string{
+		"",
+		"POINT(1 2)",
+		"POLYGON EMPTY",
+		"POLYGON((0 0, 1 0, 1 1, 0 0)",
+		"POLYGON((0 0, 1, 1 1, 0 0))",
+		"POLYGON((0 0, 1 0, 1 1, 0 0)) x",
+		"POLYGON((0 0, 1 0, 1 1e, 0 0))",
+	} {
+		if _, err := ParseWKT(wkt); err == nil {
+			t.Errorf("Expected an error for %q", wkt)
+		}
+	}
+}
+
+func TestPolylabelString(t *testing.T) {
+	point, err := PolylabelString("POLYGON((0 0, 4 0, 4 4, 0 4, 0 0))", 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertEqual(t, point, "POINT(2 2)")
+
+	if _, err := PolylabelString("POLYGON((0 0", 1.0); err == nil {
+		t.Error("Expected an error for malformed input")
+	}
+}